@@ -0,0 +1,184 @@
+package ketoclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lab259/errors/v2"
+)
+
+// DefaultTokenLeeway is how long before its expiry a token is refreshed by
+// the sources created by `NewRefreshingTokenSource`.
+const DefaultTokenLeeway = 10 * time.Second
+
+// Token is a bearer token.
+type Token struct {
+	// Value is sent in the `Authorization` header.
+	Value string
+
+	// Expiry is when the token expires. The zero value means that it never
+	// expires.
+	Expiry time.Time
+}
+
+// valid reports whether the token can still be used for `leeway`.
+func (t *Token) valid(leeway time.Duration) bool {
+	return t != nil && t.Value != "" && (t.Expiry.IsZero() || time.Now().Add(leeway).Before(t.Expiry))
+}
+
+// TokenSource supplies the bearer tokens sent with every request.
+type TokenSource interface {
+	// Token returns the token to be used by a request bound to the context.
+	Token(ctx context.Context) (*Token, error)
+}
+
+type staticTokenSource struct {
+	token *Token
+}
+
+// StaticToken returns a `TokenSource` that always supplies the same token.
+func StaticToken(token string) TokenSource {
+	return &staticTokenSource{token: &Token{Value: token}}
+}
+
+func (s *staticTokenSource) Token(context.Context) (*Token, error) {
+	return s.token, nil
+}
+
+// TokenFetcher fetches a new token, from an identity provider for instance.
+type TokenFetcher func(ctx context.Context) (*Token, error)
+
+type refreshingTokenSource struct {
+	mu     sync.Mutex
+	fetch  TokenFetcher
+	leeway time.Duration
+	token  *Token
+}
+
+// NewRefreshingTokenSource returns a `TokenSource` that keeps the token
+// fetched by `fetch` until `leeway` before its expiry, or
+// `DefaultTokenLeeway` when it is not positive. Concurrent requests share a
+// single fetch.
+func NewRefreshingTokenSource(fetch TokenFetcher, leeway time.Duration) TokenSource {
+	if leeway <= 0 {
+		leeway = DefaultTokenLeeway
+	}
+	return &refreshingTokenSource{fetch: fetch, leeway: leeway}
+}
+
+func (s *refreshingTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.valid(s.leeway) {
+		return s.token, nil
+	}
+	token, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// credentials authenticate the requests.
+type credentials interface {
+	apply(request *http.Request) error
+}
+
+type basicAuth struct {
+	username string
+	password string
+}
+
+func (c *basicAuth) apply(request *http.Request) error {
+	request.SetBasicAuth(c.username, c.password)
+	return nil
+}
+
+type bearerAuth struct {
+	source TokenSource
+}
+
+func (c *bearerAuth) apply(request *http.Request) error {
+	token, err := c.source.Token(request.Context())
+	if err != nil {
+		return errors.Wrap(err, errors.Message("bearer token"))
+	}
+	request.Header.Set("Authorization", "Bearer "+token.Value)
+	return nil
+}
+
+// authorize adds the credentials given by `WithBasicAuth`, `WithBearerToken`
+// or `WithTokenSource` to the request.
+func (client *Client) authorize(request *http.Request) error {
+	if client.credentials == nil {
+		return nil
+	}
+	return client.credentials.apply(request)
+}
+
+// ErrTLSConflict is returned by `Client.Validate` when the TLS options
+// (`WithTLSConfig`, `WithClientCertificate` or `WithRootCAs`) cannot apply to
+// a transport given to the client: one given by `WithTransport` that is not
+// an `*http.Transport`, or the hystrix client given by `WithHystrixClient`
+// when a category uses it.
+var ErrTLSConflict = errors.New("the TLS options cannot apply to a given transport")
+
+// checkTLS records the conflict of the TLS options with the transports given
+// to the client, if any, to be reported by `Validate`. It needs the breakers.
+func (client *Client) checkTLS() {
+	if client.tlsConfig == nil {
+		return
+	}
+	if _, ok := client.httpTransport.(*http.Transport); client.httpTransport != nil && !ok {
+		client.configErr = errors.Wrap(ErrTLSConflict, errors.Message(fmt.Sprintf("WithTransport with a %T", client.httpTransport)))
+		return
+	}
+	for _, category := range categories {
+		if len(client.breakers[category].commands) == 0 {
+			client.configErr = errors.Wrap(ErrTLSConflict, errors.Message("WithHystrixClient"))
+			return
+		}
+	}
+}
+
+// Validate reports the options of the client that conflict, failing with
+// `ErrTLSConflict` when the TLS options cannot apply to its transport. The
+// calls of such a client do not use the TLS options.
+func (client *Client) Validate() error {
+	return client.configErr
+}
+
+// transport returns the transport given by `WithTransport` or, when TLS is
+// configured, a transport using it. An `*http.Transport` given by
+// `WithTransport` is cloned to use the TLS configuration. It returns nil to
+// keep the default one.
+func (client *Client) transport() http.RoundTripper {
+	if client.tlsConfig == nil {
+		return client.httpTransport
+	}
+	transport, ok := client.httpTransport.(*http.Transport)
+	switch {
+	case client.httpTransport == nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case ok:
+		transport = transport.Clone()
+	default:
+		return client.httpTransport
+	}
+	transport.TLSClientConfig = client.tlsConfig
+	return transport
+}
+
+// ensureTLSConfig returns the TLS configuration of the client, creating it
+// when needed.
+func (client *Client) ensureTLSConfig() *tls.Config {
+	if client.tlsConfig == nil {
+		client.tlsConfig = &tls.Config{}
+	}
+	return client.tlsConfig
+}
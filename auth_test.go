@@ -0,0 +1,211 @@
+package ketoclient_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/gojek/heimdall/hystrix"
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// roundTripperFunc is an `http.RoundTripper` that is not an `*http.Transport`.
+type roundTripperFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// newClientCertificate creates a self signed certificate for client
+// authentication.
+func newClientCertificate() (tls.Certificate, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ketoclient"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+	certificate, err := x509.ParseCertificate(der)
+	Expect(err).ToNot(HaveOccurred())
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: certificate}, certificate
+}
+
+var _ = Describe("Authentication", func() {
+	var (
+		server        *httptest.Server
+		u             *url.URL
+		authorization chan string
+	)
+
+	BeforeEach(func() {
+		authorization = make(chan string, 10)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization <- r.Header.Get("Authorization")
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		}))
+		var err error
+		u, err = url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should send a static bearer token", func() {
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithBearerToken("s3cr3t"))
+		_, err := client.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(<-authorization).To(Equal("Bearer s3cr3t"))
+	})
+
+	It("should send the basic authentication", func() {
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithBasicAuth("snake-eyes", "s3cr3t"))
+		_, err := client.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(<-authorization).To(Equal("Basic c25ha2UtZXllczpzM2NyM3Q="))
+	})
+
+	It("should refresh the tokens before they expire", func() {
+		var fetches int64
+		source := ketoclient.NewRefreshingTokenSource(func(ctx context.Context) (*ketoclient.Token, error) {
+			n := atomic.AddInt64(&fetches, 1)
+			expiry := time.Now().Add(time.Hour)
+			if n == 1 {
+				expiry = time.Now().Add(5 * time.Second)
+			}
+			return &ketoclient.Token{Value: fmt.Sprintf("token%d", n), Expiry: expiry}, nil
+		}, 10*time.Second)
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithTokenSource(source))
+
+		for i := 0; i < 3; i++ {
+			_, err := client.HealthAlive()
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(<-authorization).To(Equal("Bearer token1"))
+		Expect(<-authorization).To(Equal("Bearer token2"))
+		Expect(<-authorization).To(Equal("Bearer token2"))
+		Expect(atomic.LoadInt64(&fetches)).To(Equal(int64(2)))
+	})
+
+	It("should fail the request when the token source fails", func() {
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithTokenSource(
+			ketoclient.NewRefreshingTokenSource(func(ctx context.Context) (*ketoclient.Token, error) {
+				return nil, errors.New("identity provider is down")
+			}, 0),
+		))
+		_, err := client.HealthAlive()
+		Expect(err).To(MatchError("bearer token: identity provider is down"))
+		Expect(authorization).To(BeEmpty())
+	})
+
+	It("should authenticate with a client certificate over mutual TLS", func() {
+		certificate, leaf := newClientCertificate()
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(leaf)
+
+		tlsServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]string{"status": r.TLS.PeerCertificates[0].Subject.CommonName})
+		}))
+		tlsServer.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+		tlsServer.StartTLS()
+		defer tlsServer.Close()
+		tlsURL, err := url.Parse(tlsServer.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		rootCAs := x509.NewCertPool()
+		rootCAs.AddCert(tlsServer.Certificate())
+
+		client := ketoclient.New(ketoclient.WithURL(tlsURL), withUniqueBreakerPrefix(), ketoclient.WithRootCAs(rootCAs), ketoclient.WithClientCertificate(certificate))
+		response, err := client.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status).To(Equal("ketoclient"))
+
+		client = ketoclient.New(ketoclient.WithURL(tlsURL), withUniqueBreakerPrefix(), ketoclient.WithRootCAs(rootCAs))
+		_, err = client.HealthAlive()
+		Expect(err).To(HaveOccurred())
+
+		client = ketoclient.New(ketoclient.WithURL(tlsURL), withUniqueBreakerPrefix(), ketoclient.WithClientCertificate(certificate))
+		_, err = client.HealthAlive()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should apply the TLS options to a given http.Transport", func() {
+		certificate, leaf := newClientCertificate()
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(leaf)
+
+		tlsServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]string{"status": r.TLS.PeerCertificates[0].Subject.CommonName})
+		}))
+		tlsServer.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+		tlsServer.StartTLS()
+		defer tlsServer.Close()
+		tlsURL, err := url.Parse(tlsServer.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		rootCAs := x509.NewCertPool()
+		rootCAs.AddCert(tlsServer.Certificate())
+
+		transport := &http.Transport{}
+		client := ketoclient.New(ketoclient.WithURL(tlsURL), withUniqueBreakerPrefix(), ketoclient.WithRootCAs(rootCAs), ketoclient.WithClientCertificate(certificate), ketoclient.WithTransport(transport))
+		Expect(client.Validate()).To(Succeed())
+		response, err := client.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status).To(Equal("ketoclient"))
+		if transport.TLSClientConfig != nil {
+			Expect(transport.TLSClientConfig.RootCAs).To(BeNil())
+		}
+	})
+
+	It("should report the TLS options conflicting with a given transport", func() {
+		rootCAs := x509.NewCertPool()
+
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithRootCAs(rootCAs), ketoclient.WithTransport(roundTripperFunc(http.DefaultTransport.RoundTrip)))
+		err := client.Validate()
+		Expect(errors.Is(err, ketoclient.ErrTLSConflict)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("WithTransport")))
+		_, err = client.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+
+		client = ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithRootCAs(rootCAs), ketoclient.WithHystrixClient(hystrix.NewClient()))
+		err = client.Validate()
+		Expect(errors.Is(err, ketoclient.ErrTLSConflict)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("WithHystrixClient")))
+
+		opts := []ketoclient.Option{ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithRootCAs(rootCAs), ketoclient.WithHystrixClient(hystrix.NewClient())}
+		for _, category := range []ketoclient.Category{ketoclient.CategoryCheck, ketoclient.CategoryRead, ketoclient.CategoryWrite, ketoclient.CategoryHealth} {
+			opts = append(opts, ketoclient.WithBreaker(category, ketoclient.BreakerSettings{}))
+		}
+		Expect(ketoclient.New(opts...).Validate()).To(Succeed())
+	})
+
+	It("should refuse to create a client from the environment with conflicting options", func() {
+		defer os.Unsetenv(ketoclient.EnvURL)
+		Expect(os.Setenv(ketoclient.EnvURL, u.String())).To(Succeed())
+
+		_, err := ketoclient.NewFromEnv(withUniqueBreakerPrefix(), ketoclient.WithRootCAs(x509.NewCertPool()), ketoclient.WithHystrixClient(hystrix.NewClient()))
+		Expect(errors.Is(err, ketoclient.ErrTLSConflict)).To(BeTrue())
+	})
+})
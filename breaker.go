@@ -0,0 +1,214 @@
+package ketoclient
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	hystrixgo "github.com/afex/hystrix-go/hystrix"
+	"github.com/gojek/heimdall/hystrix"
+)
+
+// DefaultBreakerPrefix prefixes the names of the hystrix commands created by
+// the client, one per `Category` and endpoint.
+const DefaultBreakerPrefix = "ketoclient"
+
+// defaultHTTPTimeout is the heimdall default timeout of the requests.
+const defaultHTTPTimeout = 30 * time.Second
+
+// categories lists all the categories of operations.
+var categories = []Category{CategoryCheck, CategoryRead, CategoryWrite, CategoryHealth}
+
+// BreakerState is the state of the circuit breaker of a category. With
+// several endpoints, the category is open when the circuits of all its
+// endpoints are open.
+type BreakerState string
+
+const (
+	// BreakerClosed means that requests are flowing normally.
+	BreakerClosed BreakerState = "closed"
+
+	// BreakerOpen means that requests are failing fast.
+	BreakerOpen BreakerState = "open"
+
+	// BreakerUnknown is reported for the categories that use a hystrix client
+	// given by `WithHystrixClient`, as its command cannot be inspected.
+	BreakerUnknown BreakerState = "unknown"
+)
+
+// BreakerSettings configures the hystrix command of a category. Zero values
+// keep the heimdall defaults.
+type BreakerSettings struct {
+	// Timeout is the maximum duration of a request, including the hystrix
+	// command execution.
+	Timeout time.Duration
+
+	// MaxConcurrentRequests is how many requests can run at the same time.
+	MaxConcurrentRequests int
+
+	// RequestVolumeThreshold is the minimum number of requests needed before
+	// the circuit can be tripped.
+	RequestVolumeThreshold int
+
+	// SleepWindow is how long to wait after the circuit opens before testing
+	// for recovery.
+	SleepWindow time.Duration
+
+	// ErrorPercentThreshold opens the circuit once the rolling measure of
+	// errors exceeds this percent of requests.
+	ErrorPercentThreshold int
+}
+
+func (s BreakerSettings) options() []hystrix.Option {
+	opts := make([]hystrix.Option, 0, 5)
+	if s.Timeout > 0 {
+		opts = append(opts, hystrix.WithHTTPTimeout(s.Timeout), hystrix.WithHystrixTimeout(s.Timeout))
+	}
+	if s.MaxConcurrentRequests > 0 {
+		opts = append(opts, hystrix.WithMaxConcurrentRequests(s.MaxConcurrentRequests))
+	}
+	if s.RequestVolumeThreshold > 0 {
+		opts = append(opts, hystrix.WithRequestVolumeThreshold(s.RequestVolumeThreshold))
+	}
+	if s.SleepWindow > 0 {
+		opts = append(opts, hystrix.WithSleepWindow(int(s.SleepWindow/time.Millisecond)))
+	}
+	if s.ErrorPercentThreshold > 0 {
+		opts = append(opts, hystrix.WithErrorPercentThreshold(s.ErrorPercentThreshold))
+	}
+	return opts
+}
+
+// httpTimeout is the timeout of the HTTP client of the command, which is
+// only set by the client when it has its own transport.
+func (s BreakerSettings) httpTimeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return defaultHTTPTimeout
+}
+
+// BreakerListener is called whenever the circuit breaker of a category
+// changes its state.
+type BreakerListener func(category Category, from, to BreakerState)
+
+// breaker holds the hystrix clients of a category, one per endpoint so a
+// failing replica does not open the circuit of the healthy ones.
+type breaker struct {
+	// open is accessed atomically. 1 means open.
+	open int32
+
+	// commands are the hystrix command names, by endpoint index. It is empty
+	// when the client was given by `WithHystrixClient`.
+	commands []string
+	clients  []*hystrix.Client
+}
+
+// client returns the hystrix client of the endpoint.
+func (b *breaker) client(e *endpoint) *hystrix.Client {
+	if len(b.clients) == 1 {
+		return b.clients[0]
+	}
+	return b.clients[e.index]
+}
+
+func (b *breaker) state() BreakerState {
+	if len(b.commands) == 0 {
+		return BreakerUnknown
+	}
+	for _, command := range b.commands {
+		circuit, _, err := hystrixgo.GetCircuit(command)
+		if err != nil || !circuit.IsOpen() {
+			return BreakerClosed
+		}
+	}
+	return BreakerOpen
+}
+
+// initBreakers creates the hystrix clients of each category.
+//
+// Categories configured by `WithBreaker` get their own hystrix commands. The
+// others share the client given by `WithHystrixClient` or, when there is
+// none, also get their own commands with the heimdall defaults. The commands
+// are named after the prefix and the category, followed by the index of the
+// endpoint when there are several. The clients created here share the
+// transport configured by the TLS options.
+func (client *Client) initBreakers() {
+	if client.breakerPrefix == "" {
+		client.breakerPrefix = DefaultBreakerPrefix
+	}
+	client.breakers = make(map[Category]*breaker, len(categories))
+	transport := client.transport()
+	endpoints := client.endpoints.endpoints
+	for _, category := range categories {
+		settings, ok := client.breakerSettings[category]
+		if !ok && client.client != nil {
+			client.breakers[category] = &breaker{clients: []*hystrix.Client{client.client}}
+			continue
+		}
+		b := &breaker{
+			commands: make([]string, len(endpoints)),
+			clients:  make([]*hystrix.Client, len(endpoints)),
+		}
+		for i := range endpoints {
+			command := client.breakerPrefix + "." + string(category)
+			if len(endpoints) > 1 {
+				command += "." + strconv.Itoa(i)
+			}
+			opts := append([]hystrix.Option{hystrix.WithCommandName(command)}, settings.options()...)
+			if transport != nil {
+				opts = append(opts, hystrix.WithHTTPClient(&http.Client{
+					Transport: transport,
+					Timeout:   settings.httpTimeout(),
+				}))
+			}
+			b.commands[i] = command
+			b.clients[i] = hystrix.NewClient(opts...)
+		}
+		client.breakers[category] = b
+	}
+}
+
+// observeBreaker checks the state of the breaker of the category, notifying
+// the listeners if it changed.
+func (client *Client) observeBreaker(category Category) {
+	b := client.breakers[category]
+	if len(b.commands) == 0 || len(client.breakerListeners) == 0 {
+		return
+	}
+	var open int32
+	if b.state() == BreakerOpen {
+		open = 1
+	}
+	if atomic.SwapInt32(&b.open, open) == open {
+		return
+	}
+	from, to := BreakerClosed, BreakerOpen
+	if open == 0 {
+		from, to = to, from
+	}
+	for _, listener := range client.breakerListeners {
+		listener(category, from, to)
+	}
+}
+
+// BreakerState returns the current state of the circuit breaker of a
+// category.
+func (client *Client) BreakerState(category Category) BreakerState {
+	b, ok := client.breakers[category]
+	if !ok {
+		return BreakerUnknown
+	}
+	return b.state()
+}
+
+// BreakerStates returns the current state of the circuit breakers of all
+// categories.
+func (client *Client) BreakerStates() map[Category]BreakerState {
+	r := make(map[Category]BreakerState, len(categories))
+	for _, category := range categories {
+		r[category] = client.BreakerState(category)
+	}
+	return r
+}
@@ -0,0 +1,112 @@
+package ketoclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gojek/heimdall/hystrix"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Breakers", func() {
+	var (
+		server *httptest.Server
+		u      *url.URL
+		prefix string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"code":500,"message":"database is down"}`))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		var err error
+		u, err = url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		prefix = fmt.Sprintf("ketoclient-test-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should create a hystrix command per category", func() {
+		client := New(WithURL(u), WithBreakerPrefix(prefix))
+		for _, category := range categories {
+			Expect(client.breakers[category].commands).To(Equal([]string{prefix + "." + string(category)}))
+		}
+		Expect(client.BreakerStates()).To(Equal(map[Category]BreakerState{
+			CategoryCheck:  BreakerClosed,
+			CategoryRead:   BreakerClosed,
+			CategoryWrite:  BreakerClosed,
+			CategoryHealth: BreakerClosed,
+		}))
+	})
+
+	It("should share the given hystrix client with the categories without settings", func() {
+		hc := hystrix.NewClient()
+		client := New(
+			WithURL(u),
+			WithHystrixClient(hc),
+			WithBreakerPrefix(prefix),
+			WithBreaker(CategoryCheck, BreakerSettings{}),
+		)
+		Expect(client.breakers[CategoryCheck].clients).ToNot(ContainElement(hc))
+		Expect(client.breakers[CategoryRead].clients).To(Equal([]*hystrix.Client{hc}))
+		Expect(client.BreakerState(CategoryCheck)).To(Equal(BreakerClosed))
+		Expect(client.BreakerState(CategoryRead)).To(Equal(BreakerUnknown))
+	})
+
+	It("should not open the check circuit when writes fail", func() {
+		var (
+			mu      sync.Mutex
+			changes []string
+		)
+		client := New(
+			WithURL(u),
+			WithBreakerPrefix(prefix),
+			WithBreaker(CategoryWrite, BreakerSettings{
+				RequestVolumeThreshold: 1,
+				ErrorPercentThreshold:  1,
+				SleepWindow:            time.Minute,
+			}),
+			WithBreakerListener(func(category Category, from, to BreakerState) {
+				mu.Lock()
+				defer mu.Unlock()
+				changes = append(changes, fmt.Sprintf("%s: %s -> %s", category, from, to))
+			}),
+		)
+
+		for i := 0; i < 3; i++ {
+			_, err := client.UpsertOryAccessControlRole(Exact, &UpsertORYAccessRoleRequest{
+				Role: ORYAccessControlRole{ID: "role1"},
+			})
+			Expect(err).To(HaveOccurred())
+		}
+		Expect(client.BreakerState(CategoryWrite)).To(Equal(BreakerOpen))
+
+		response, err := client.AllowedOryAccessControlPolicy(Exact, &AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: "blog1:post:33",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Allowed).To(BeTrue())
+		Expect(client.BreakerState(CategoryCheck)).To(Equal(BreakerClosed))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(changes).To(Equal([]string{"write: closed -> open"}))
+	})
+})
@@ -0,0 +1,158 @@
+package ketoclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Outcomes of the operations reported by the metrics.
+const (
+	outcomeOK      = "ok"
+	outcomeAllowed = "allowed"
+	outcomeDenied  = "denied"
+	outcomeError   = "error"
+)
+
+type callKey struct{}
+
+// call is an operation of the client in progress. It feeds the span, the
+// metrics and the logs of the operation.
+type call struct {
+	// Accessed atomically, as the request of a deduplicated check may run in
+	// another goroutine.
+	status int64
+
+	client    *Client
+	ctx       context.Context
+	operation string
+	flavor    Flavor
+	attrs     []attribute.KeyValue
+	span      trace.Span
+	startedAt time.Time
+	outcome   string
+	options   callOptions
+	cancel    context.CancelFunc
+
+	// checkContext is the `Context` of an authorization check, logged after
+	// its redaction.
+	checkContext interface{}
+}
+
+// start starts an operation and returns a copy of the client bound to its
+// context, so the requests made by the operation belong to it.
+//
+// The context is limited by the timeout of the call options, if any.
+func (client *Client) start(operation string, flavor Flavor, opts []CallOption, attrs ...attribute.KeyValue) (*Client, *call) {
+	attrs = append([]attribute.KeyValue{AttributeOperation.String(operation)}, attrs...)
+	if flavor != "" {
+		attrs = append(attrs, AttributeFlavor.String(string(flavor)))
+	}
+	ctx, span := client.tracer.Start(client.context(), "keto."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	c := &call{
+		client:    client,
+		operation: operation,
+		flavor:    flavor,
+		attrs:     attrs,
+		span:      span,
+		startedAt: time.Now(),
+		options:   newCallOptions(client.callOptions, opts),
+	}
+	if c.options.timeout > 0 {
+		ctx, c.cancel = context.WithTimeout(ctx, c.options.timeout)
+	}
+	c.ctx = context.WithValue(ctx, callKey{}, c)
+	client.metrics.begin(c)
+	return client.WithContext(c.ctx), c
+}
+
+// callFromContext returns the operation a request belongs to, if any.
+func callFromContext(ctx context.Context) *call {
+	c, _ := ctx.Value(callKey{}).(*call)
+	return c
+}
+
+// cacheable reports whether the call may be deduplicated or served from the
+// stored decisions.
+func (c *call) cacheable() bool {
+	return c == nil || !c.options.noCache
+}
+
+// decide records the decision of an authorization check.
+func (c *call) decide(response *AllowedORYAccessControlPolicyResponse) {
+	c.outcome = outcomeDenied
+	if response.Allowed {
+		c.outcome = outcomeAllowed
+	}
+	c.span.SetAttributes(AttributeAllowed.Bool(response.Allowed), AttributeStale.Bool(response.Stale || response.Defaulted))
+}
+
+// end records the error, if any, and finishes the operation. It takes a
+// pointer so it can be deferred with the named error result of the
+// operation.
+func (c *call) end(err *error) {
+	if *err != nil {
+		c.outcome = outcomeError
+		c.span.RecordError(*err)
+		c.span.SetStatus(codes.Error, (*err).Error())
+	} else if c.outcome == "" {
+		c.outcome = outcomeOK
+	}
+	c.span.End()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	duration := time.Since(c.startedAt)
+	c.client.metrics.end(c, duration)
+	if *err != nil {
+		c.log(c.client.logLevels.Failure, "keto operation failed", "error", *err, "duration", duration)
+	}
+}
+
+// request records a request made by the operation. The URL is left out, as
+// its path may carry subjects.
+func (c *call) request(request *http.Request, endpoint string) {
+	if c == nil {
+		return
+	}
+	c.log(c.client.logLevels.Request, "keto request",
+		string(attributeHTTPMethod), request.Method,
+		string(attributeServerAddress), endpoint,
+	)
+}
+
+// response records the response, or the error, of a request made by the
+// operation.
+func (c *call) response(response *http.Response, err error, duration time.Duration) {
+	if c == nil {
+		return
+	}
+	args := []interface{}{"duration", duration}
+	if response != nil {
+		atomic.StoreInt64(&c.status, int64(response.StatusCode))
+		args = append(args, string(attributeHTTPStatus), response.StatusCode)
+	}
+	if err != nil {
+		args = append(args, "error", err)
+	}
+	c.log(c.client.logLevels.Response, "keto response", args...)
+}
+
+// statusLabel returns the status code of the last response of the operation,
+// or an empty string when the server did not respond.
+func (c *call) statusLabel() string {
+	status := atomic.LoadInt64(&c.status)
+	if status == 0 {
+		return ""
+	}
+	return strconv.FormatInt(status, 10)
+}
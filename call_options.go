@@ -0,0 +1,200 @@
+package ketoclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	hystrixgo "github.com/afex/hystrix-go/hystrix"
+	"github.com/lab259/errors/v2"
+)
+
+// DefaultRetryBackoff is the backoff between the attempts of the calls
+// retried by `CallRetries` when none is given.
+var DefaultRetryBackoff = Backoff{
+	Initial:    20 * time.Millisecond,
+	Max:        time.Second,
+	Multiplier: 2,
+}
+
+// CallOption overrides the configuration of the client for a single call.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	timeout        time.Duration
+	retries        int
+	backoff        Backoff
+	header         http.Header
+	idempotencyKey string
+	noCache        bool
+}
+
+// newCallOptions applies the options of the call over the defaults of the
+// client.
+func newCallOptions(defaults, opts []CallOption) callOptions {
+	options := callOptions{backoff: DefaultRetryBackoff}
+	for _, opt := range defaults {
+		opt(&options)
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// CallTimeout creates a call option that limits the duration of the call,
+// retries included, on top of the deadline of the context of the client.
+func CallTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// CallRetries creates a call option that retries the request up to `retries`
+// times when it fails to reach the server or the server fails (5xx), waiting
+// according to the backoff, or `DefaultRetryBackoff` when none is given.
+// With several endpoints (see `WithURLs`), each retry picks its endpoint
+// again, so it goes to another replica when the failing one was ejected.
+//
+// Writes are only retried when the call has an idempotency key (see
+// `CallIdempotencyKey`), as a write that failed may have been applied.
+func CallRetries(retries int, backoff ...Backoff) CallOption {
+	return func(o *callOptions) {
+		o.retries = retries
+		if len(backoff) > 0 {
+			o.backoff = backoff[0]
+		}
+	}
+}
+
+// CallHeader creates a call option that adds a header to the request of the
+// call.
+func CallHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// CallIdempotencyKey creates a call option that sends the key in the
+// `Idempotency-Key` header, the same for all the attempts of the call, so
+// gateways can recognize retried writes. It allows writes to be retried.
+func CallIdempotencyKey(key string) CallOption {
+	return func(o *callOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// CallNoCache creates a call option that makes the call reach the server:
+// it is not deduplicated (see `WithCheckDeduplication`), it is not replaced by
+// a stored decision when it fails (see `WithStaleOnError`) and it is sent
+// with `Cache-Control: no-cache`. A successful decision is still stored.
+func CallNoCache() CallOption {
+	return func(o *callOptions) {
+		o.noCache = true
+	}
+}
+
+// apply adds the headers of the options to the request.
+func (o *callOptions) apply(request *http.Request) {
+	for key, values := range o.header {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+	if o.idempotencyKey != "" {
+		request.Header.Set("Idempotency-Key", o.idempotencyKey)
+	}
+	if o.noCache {
+		request.Header.Set("Cache-Control", "no-cache")
+	}
+}
+
+// attempts returns how many times a request of the category can be sent.
+func (o *callOptions) attempts(category Category) int {
+	if o.retries <= 0 || (category == CategoryWrite && o.idempotencyKey == "") {
+		return 1
+	}
+	return o.retries + 1
+}
+
+// sendWithRetries sends the request, retrying it as allowed by the options of
+// the call.
+//
+// Every attempt picks its endpoint, so a retry goes to a healthy replica
+// rather than to the one that just failed, unless the client targets a single
+// endpoint (see `CheckEndpoints`).
+func (client *Client) sendWithRetries(category Category, e *endpoint, request *http.Request, options *callOptions) (*http.Response, error) {
+	ctx := request.Context()
+	attempts := options.attempts(category)
+	failover := client.target == nil && len(client.endpoints.endpoints) > 1
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		response, err := client.send(category, e, request)
+		if attempt == attempts || !retryable(ctx, response, err, failover) || (request.Body != nil && request.GetBody == nil) {
+			return response, err
+		}
+		drain(response)
+
+		delay = options.backoff.next(delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if err == nil {
+				err = ctx.Err()
+			}
+			return nil, err
+		case <-timer.C:
+		}
+
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			request.Body = body
+		}
+		if failover {
+			e = retarget(request, e, client.endpoints.pick(category))
+		}
+	}
+}
+
+// retarget points the request to another endpoint, keeping its path and its
+// query, and returns the endpoint the request is sent to. The request is kept
+// as it is when an interceptor sent it somewhere else.
+func retarget(request *http.Request, from, to *endpoint) *endpoint {
+	if from == to {
+		return from
+	}
+	rest, ok := strings.CutPrefix(request.URL.String(), from.url)
+	if !ok {
+		return from
+	}
+	u, err := url.Parse(to.url + rest)
+	if err != nil {
+		return from
+	}
+	if request.Host == request.URL.Host {
+		request.Host = u.Host
+	}
+	request.URL = u
+	return to
+}
+
+// retryable reports whether a failed attempt is worth retrying: the server
+// failed or could not be reached, but neither the caller gave up nor the
+// client refused to send it. An open circuit is only worth retrying on
+// another endpoint, when the request can fail over.
+func retryable(ctx context.Context, response *http.Response, err error, failover bool) bool {
+	if err != nil {
+		_, circuit := err.(hystrixgo.CircuitError)
+		return (failover || !circuit) && ctx.Err() == nil && !errors.Is(err, ErrThrottled)
+	}
+	return response.StatusCode >= http.StatusInternalServerError
+}
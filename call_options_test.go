@@ -0,0 +1,149 @@
+package ketoclient_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CallOption", func() {
+	var (
+		server   *httptest.Server
+		client   *ketoclient.Client
+		failures int64
+		delay    int64
+		mu       sync.Mutex
+		headers  []http.Header
+	)
+
+	backoff := ketoclient.Backoff{Initial: time.Millisecond, Multiplier: 1}
+
+	BeforeEach(func() {
+		atomic.StoreInt64(&failures, 0)
+		atomic.StoreInt64(&delay, 0)
+		headers = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			headers = append(headers, r.Header)
+			mu.Unlock()
+			time.Sleep(time.Duration(atomic.LoadInt64(&delay)))
+			if atomic.AddInt64(&failures, -1) >= 0 {
+				writeJSON(w, http.StatusInternalServerError, &ketoclient.ResponseError{Code: 500, Message: "database is down"})
+				return
+			}
+			switch r.Method {
+			case http.MethodDelete:
+				w.WriteHeader(http.StatusNoContent)
+			case http.MethodPost:
+				writeJSON(w, http.StatusOK, &ketoclient.AllowedORYAccessControlPolicyResponse{Allowed: true})
+			default:
+				writeJSON(w, http.StatusOK, map[string]string{"id": "policy1"})
+			}
+		}))
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		client = ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	requests := func() []http.Header {
+		mu.Lock()
+		defer mu.Unlock()
+		return headers
+	}
+
+	It("should limit the duration of the call", func() {
+		atomic.StoreInt64(&delay, int64(200*time.Millisecond))
+		start := time.Now()
+		_, err := client.GetOryAccessControlPolicy(ketoclient.Exact, "policy1", ketoclient.CallTimeout(20*time.Millisecond))
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 150*time.Millisecond))
+
+		atomic.StoreInt64(&delay, 0)
+		_, err = client.GetOryAccessControlPolicy(ketoclient.Exact, "policy1")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should retry the reads that fail", func() {
+		atomic.StoreInt64(&failures, 2)
+		response, err := client.GetOryAccessControlPolicy(ketoclient.Exact, "policy1", ketoclient.CallRetries(2, backoff))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Policy.ID).To(Equal("policy1"))
+		Expect(requests()).To(HaveLen(3))
+	})
+
+	It("should give up after the retries", func() {
+		atomic.StoreInt64(&failures, 3)
+		_, err := client.GetOryAccessControlPolicy(ketoclient.Exact, "policy1", ketoclient.CallRetries(2, backoff))
+		Expect(err).To(BeAssignableToTypeOf(&ketoclient.ResponseError{}))
+		Expect(requests()).To(HaveLen(3))
+	})
+
+	It("should only retry the writes with an idempotency key", func() {
+		atomic.StoreInt64(&failures, 1)
+		Expect(client.DeleteOryAccessControlPolicy(ketoclient.Exact, "policy1", ketoclient.CallRetries(2, backoff))).ToNot(Succeed())
+		Expect(requests()).To(HaveLen(1))
+
+		atomic.StoreInt64(&failures, 1)
+		Expect(client.DeleteOryAccessControlPolicy(ketoclient.Exact, "policy1", ketoclient.CallRetries(2, backoff), ketoclient.CallIdempotencyKey("delete-policy1"))).To(Succeed())
+		Expect(requests()).To(HaveLen(3))
+		Expect(requests()[1].Get("Idempotency-Key")).To(Equal("delete-policy1"))
+		Expect(requests()[2].Get("Idempotency-Key")).To(Equal("delete-policy1"))
+	})
+
+	It("should resend the body of the retried checks", func() {
+		atomic.StoreInt64(&failures, 1)
+		response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: "blog1:post:33",
+		}, ketoclient.CallRetries(1, backoff))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Allowed).To(BeTrue())
+		Expect(requests()).To(HaveLen(2))
+	})
+
+	It("should add the headers of the call", func() {
+		_, err := client.HealthAlive(ketoclient.CallHeader("X-Request-Id", "42"), ketoclient.CallHeader("X-Request-Id", "43"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(requests()[0]["X-Request-Id"]).To(Equal([]string{"42", "43"}))
+		Expect(requests()[1]).ToNot(HaveKey("X-Request-Id"))
+	})
+
+	It("should bypass the stored decisions", func() {
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		client = ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithStaleOnError(ketoclient.StaleOnError{
+			Window: time.Minute,
+		}))
+		request := &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: "blog1:post:33",
+		}
+		_, err = client.AllowedOryAccessControlPolicy(ketoclient.Exact, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		atomic.StoreInt64(&failures, 2)
+		_, err = client.AllowedOryAccessControlPolicy(ketoclient.Exact, request, ketoclient.CallNoCache())
+		Expect(err).To(HaveOccurred())
+		Expect(requests()[1].Get("Cache-Control")).To(Equal("no-cache"))
+
+		response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Stale).To(BeTrue())
+	})
+})
@@ -1,17 +1,23 @@
 package ketoclient
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/lab259/errors/v2"
 
 	"github.com/blang/semver"
 	"github.com/gojek/heimdall/hystrix"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const clientVersionCompatibility = ">=0.3.0"
@@ -23,20 +29,79 @@ var (
 	ErrServerIncompatible = errors.New("server incompatible. required: " + clientVersionCompatibility)
 )
 
+// UnexpectedResponse is returned when the server answers with a status the
+// operation does not expect. It keeps a copy of the response, as the
+// response itself is closed before the error is returned.
 type UnexpectedResponse struct {
-	Response *http.Response
+	StatusCode int
+	Status     string
+	Header     http.Header
+
+	// Body is the beginning of the body of the response, up to 4KiB.
+	Body []byte
 }
 
 func (err *UnexpectedResponse) Error() string {
-	return fmt.Sprintf("unexpected status %s", http.StatusText(err.Response.StatusCode))
+	return fmt.Sprintf("unexpected status %s", http.StatusText(err.StatusCode))
 }
 
 type Client struct {
-	url    url.URL
-	_url   string
-	client *hystrix.Client
+	url              url.URL
+	_url             string
+	client           *hystrix.Client
+	ctx              context.Context
+	throttles        map[Category]*throttle
+	breakers         map[Category]*breaker
+	breakerPrefix    string
+	breakerSettings  map[Category]BreakerSettings
+	breakerListeners []BreakerListener
+	features         *featureDetector
+	urls             []url.URL
+	balancing        Balancing
+	ejectionCooldown time.Duration
+	endpoints        *endpointPool
+	target           *endpoint
+	stale            *staleFallback
+	dedup            *dedup
+	tracer           trace.Tracer
+	propagator       propagation.TextMapPropagator
+	subjectRedactor  Redactor
+	metrics          *metrics
+	metricsLabels    prometheus.Labels
+	resourceRedactor Redactor
+	contextRedactor  ContextRedactor
+	logger           Logger
+	logLevels        LogLevels
+	credentials      credentials
+	tlsConfig        *tls.Config
+	httpTransport    http.RoundTripper
+	interceptors     []Interceptor
+	callOptions      []CallOption
+	defaultFlavor    Flavor
+
+	// configErr is the conflict of the options reported by `Validate`.
+	configErr error
 }
 
+// Category groups the operations of the client by their nature, so they can
+// be configured independently.
+type Category string
+
+const (
+	// CategoryCheck is the authorization check (`AllowedOryAccessControlPolicy`).
+	CategoryCheck Category = "check"
+
+	// CategoryRead are the operations that get or list policies and roles.
+	CategoryRead Category = "read"
+
+	// CategoryWrite are the administrative operations that change policies
+	// and roles.
+	CategoryWrite Category = "write"
+
+	// CategoryHealth are the health and version endpoints.
+	CategoryHealth Category = "health"
+)
+
 type Flavor string
 
 const (
@@ -57,38 +122,162 @@ const (
 	Regex Flavor = "regex"
 )
 
+// WithContext returns a shallow copy of the client whose requests are bound
+// to the given context. The copy shares the configuration, connections and
+// limits of the original client.
+func (client *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+	c := *client
+	c.ctx = ctx
+	return &c
+}
+
+func (client *Client) context() context.Context {
+	if client.ctx == nil {
+		return context.Background()
+	}
+	return client.ctx
+}
+
+// invoke builds the request of an operation and passes it through the
+// interceptors given by `WithInterceptors`. The innermost handler sends the
+// request and decodes its response, whose body is always drained and closed
+// afterwards so the connection can be reused.
+func invoke[T any](client *Client, category Category, method, path string, body io.Reader, decode func(response *http.Response) (T, error)) (T, error) {
+	var zero T
+	ctx := client.context()
+
+	// All endpoints but the health ones belong to the ACP engines.
+	if category != CategoryHealth {
+		if err := client.require(FeatureACP); err != nil {
+			return zero, err
+		}
+	}
+
+	e := client.target
+	if e == nil {
+		e = client.endpoints.pick(category)
+	}
+	request, err := http.NewRequest(method, e.url+path, body)
+	if err != nil {
+		return zero, err
+	}
+
+	invocation := &Invocation{
+		Category: category,
+		Request:  request.WithContext(ctx),
+	}
+	options := &callOptions{}
+	if c := callFromContext(ctx); c != nil {
+		invocation.Operation = c.operation
+		invocation.Flavor = c.flavor
+		options = &c.options
+	}
+	options.apply(invocation.Request)
+
+	result, err := client.intercept(invocation, func(invocation *Invocation) (interface{}, error) {
+		response, err := client.sendWithRetries(category, e, invocation.Request, options)
+		if err != nil {
+			return nil, err
+		}
+		defer drain(response)
+		result, err := decode(response)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	})
+	if err != nil {
+		return zero, err
+	}
+	return resultAs[T](invocation, result)
+}
+
+// send makes a request to one of the Keto endpoints, applying the limits and
+// the circuit breaker configured for the category.
+func (client *Client) send(category Category, e *endpoint, request *http.Request) (*http.Response, error) {
+	ctx := request.Context()
+
+	release, err := client.throttles[category].acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := client.authorize(request); err != nil {
+		return nil, err
+	}
+	client.traceRequest(request, e.url)
+	call := callFromContext(ctx)
+	call.request(request, e.url)
+
+	atomic.AddInt64(&e.inFlight, 1)
+	defer atomic.AddInt64(&e.inFlight, -1)
+	defer client.observeBreaker(category)
+
+	sentAt := time.Now()
+	response, err := client.breakers[category].client(e).Do(request)
+	client.endpoints.observe(ctx, e, err)
+	traceResponse(request, response)
+	call.response(response, err, time.Since(sentAt))
+	if err != nil && response != nil {
+		drain(response)
+		response = nil
+	}
+	return response, err
+}
+
 // AllowedOryAccessControlPolicy check if a request is allowed.
 //
+// When `WithCheckDeduplication` is used, identical concurrent checks share a
+// single request. When `WithStaleOnError` is used, failures are replaced by
+// the last known decision for the same request or by the configured default.
+// `CallNoCache` disables both for a call.
+//
 // See Also https://www.ory.sh/docs/keto/sdk/api#check-if-a-request-is-allowed
-func (client *Client) AllowedOryAccessControlPolicy(flavor Flavor, request *AllowedORYAccessControlPolicyRequest) (*AllowedORYAccessControlPolicyResponse, error) {
-	buf := bytes.NewBuffer(nil)
-	enc := json.NewEncoder(buf)
-	err := enc.Encode(request)
-	if err != nil {
+func (client *Client) AllowedOryAccessControlPolicy(flavor Flavor, request *AllowedORYAccessControlPolicyRequest, opts ...CallOption) (response *AllowedORYAccessControlPolicyResponse, err error) {
+	if err = validateAllowed(flavor, request); err != nil {
 		return nil, err
 	}
 
-	response, err := client.client.Post(client._url+"/engines/acp/ory/"+string(flavor)+"/allowed", buf, nil)
+	// The fallback only gives up on the context of the caller, not on the
+	// timeout of the call.
+	parent := client.context()
+	client, call := client.start("AllowedOryAccessControlPolicy", flavor, opts, client.subject(request.Subject), AttributeAction.String(request.Action), client.resource(request.Resource))
+	call.checkContext = request.Context
+	defer call.end(&err)
+
+	response, err = client.deduplicatedAllowedOryAccessControlPolicy(flavor, request)
+	if client.stale != nil {
+		response, err = client.stale.resolve(parent, flavor, request, response, err, call.cacheable())
+	}
+	if response != nil {
+		call.decide(response)
+	}
+	return response, err
+}
+
+// deduplicatedAllowedOryAccessControlPolicy collapses identical concurrent
+// checks when `WithCheckDeduplication` is used.
+func (client *Client) deduplicatedAllowedOryAccessControlPolicy(flavor Flavor, request *AllowedORYAccessControlPolicyRequest) (*AllowedORYAccessControlPolicyResponse, error) {
+	if client.dedup == nil || !callFromContext(client.context()).cacheable() {
+		return client.allowedOryAccessControlPolicy(flavor, request)
+	}
+	key, err := decisionKey(flavor, request)
 	if err != nil {
 		return nil, err
 	}
+	return client.dedup.do(client, key, func() (*AllowedORYAccessControlPolicyResponse, error) {
+		shared, cancel := client.dedup.shared(client)
+		defer cancel()
+		return shared.allowedOryAccessControlPolicy(flavor, request)
+	})
+}
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		return &AllowedORYAccessControlPolicyResponse{Allowed: true}, nil
-	case http.StatusForbidden:
-		return &AllowedORYAccessControlPolicyResponse{Allowed: false}, nil
-	case http.StatusInternalServerError:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return nil, r
-	default:
-		return nil, &UnexpectedResponse{Response: response}
-	}
+func (client *Client) allowedOryAccessControlPolicy(flavor Flavor, request *AllowedORYAccessControlPolicyRequest) (*AllowedORYAccessControlPolicyResponse, error) {
+	return routeAllowed.call(client, pathParams{"flavor": string(flavor)}, nil, request)
 }
 
 // UpsertOryAccessControlPolicy an ORY Access Control Policy.
@@ -100,38 +289,15 @@ func (client *Client) AllowedOryAccessControlPolicy(flavor Flavor, request *Allo
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#upsertoryaccesscontrolpolicy
-func (client *Client) UpsertOryAccessControlPolicy(flavor Flavor, request *UpsertORYAccessPolicyRequest) (*UpsertORYAccessPolicyResponseOK, error) {
-	buf := bytes.NewBuffer(nil)
-	enc := json.NewEncoder(buf)
-	err := enc.Encode(request)
-	if err != nil {
+func (client *Client) UpsertOryAccessControlPolicy(flavor Flavor, request *UpsertORYAccessPolicyRequest, opts ...CallOption) (_ *UpsertORYAccessPolicyResponseOK, err error) {
+	if err = validateUpsertPolicy(flavor, request); err != nil {
 		return nil, err
 	}
 
-	response, err := client.client.Put(client._url+"/engines/acp/ory/"+string(flavor)+"/policies", buf, nil)
-	if err != nil {
-		return nil, err
-	}
+	client, call := client.start("UpsertOryAccessControlPolicy", flavor, opts, AttributePolicyID.String(request.ID))
+	defer call.end(&err)
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		r := &UpsertORYAccessPolicyResponseOK{}
-		err := json.NewDecoder(response.Body).Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
-	case http.StatusInternalServerError:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return nil, r
-	default:
-		return nil, &UnexpectedResponse{Response: response}
-	}
+	return routeUpsertPolicy.call(client, pathParams{"flavor": string(flavor)}, nil, request)
 }
 
 // ListOryAccessControlPolicy list ORY Access Control Policies.
@@ -142,48 +308,15 @@ func (client *Client) UpsertOryAccessControlPolicy(flavor Flavor, request *Upser
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#listoryaccesscontrolpolicies
-func (client *Client) ListOryAccessControlPolicy(flavor Flavor, request *ListORYAccessPolicyRequest) (*ListORYAccessPolicyResponseOK, error) {
-	s := ""
-	if request.Limit > 0 {
-		s += fmt.Sprintf("limit=%d", request.Limit)
-	}
-	if request.Offset > 0 {
-		if s != "" {
-			s += "&"
-		}
-		s += fmt.Sprintf("offset=%d", request.Offset)
-	}
-
-	if s != "" {
-		s = "?" + s
-	}
-
-	response, err := client.client.Get(client._url+"/engines/acp/ory/"+string(flavor)+"/policies"+s, nil)
-	if err != nil {
+func (client *Client) ListOryAccessControlPolicy(flavor Flavor, request *ListORYAccessPolicyRequest, opts ...CallOption) (_ *ListORYAccessPolicyResponseOK, err error) {
+	if err = validateListPolicies(flavor, request); err != nil {
 		return nil, err
 	}
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		r := &ListORYAccessPolicyResponseOK{
-			Policies: make([]ORYAccessControlPolicy, 0, 10),
-		}
-		err := json.NewDecoder(response.Body).Decode(&r.Policies)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
-	case http.StatusInternalServerError:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return nil, r
-	default:
-		return nil, &UnexpectedResponse{Response: response}
-	}
+	client, call := client.start("ListOryAccessControlPolicy", flavor, opts)
+	defer call.end(&err)
+
+	return routeListPolicies.call(client, pathParams{"flavor": string(flavor)}, pageQuery(request.Limit, request.Offset), nil)
 }
 
 // GetOryAccessControlPolicy list ORY Access Control Policies.
@@ -194,33 +327,15 @@ func (client *Client) ListOryAccessControlPolicy(flavor Flavor, request *ListORY
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#getoryaccesscontrolpolicy
-func (client *Client) GetOryAccessControlPolicy(flavor Flavor, id string) (*GetORYAccessPolicyResponseOK, error) {
-	response, err := client.client.Get(client._url+"/engines/acp/ory/"+string(flavor)+"/policies/"+id, nil)
-	if err != nil {
+func (client *Client) GetOryAccessControlPolicy(flavor Flavor, id string, opts ...CallOption) (_ *GetORYAccessPolicyResponseOK, err error) {
+	if err = validateID("GetOryAccessControlPolicy", flavor, id); err != nil {
 		return nil, err
 	}
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		r := &GetORYAccessPolicyResponseOK{}
-		err := json.NewDecoder(response.Body).Decode(&r.Policy)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
-	case http.StatusNotFound:
-		return nil, ErrNotFound
-	case http.StatusInternalServerError:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return nil, r
-	default:
-		return nil, &UnexpectedResponse{Response: response}
-	}
+	client, call := client.start("GetOryAccessControlPolicy", flavor, opts, AttributePolicyID.String(id))
+	defer call.end(&err)
+
+	return routeGetPolicy.call(client, pathParams{"flavor": string(flavor), "id": id}, nil, nil)
 }
 
 // DeleteOryAccessControlPolicy deletes an ORY Access Control Policy.
@@ -231,26 +346,16 @@ func (client *Client) GetOryAccessControlPolicy(flavor Flavor, id string) (*GetO
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#deleteoryaccesscontrolpolicy
-func (client *Client) DeleteOryAccessControlPolicy(flavor Flavor, id string) error {
-	response, err := client.client.Delete(client._url+"/engines/acp/ory/"+string(flavor)+"/policies/"+id, nil)
-	if err != nil {
+func (client *Client) DeleteOryAccessControlPolicy(flavor Flavor, id string, opts ...CallOption) (err error) {
+	if err = validateID("DeleteOryAccessControlPolicy", flavor, id); err != nil {
 		return err
 	}
 
-	switch response.StatusCode {
-	case http.StatusNoContent:
-		return nil
-	case http.StatusInternalServerError:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return err
-		}
-		return r
-	default:
-		return &UnexpectedResponse{Response: response}
-	}
+	client, call := client.start("DeleteOryAccessControlPolicy", flavor, opts, AttributePolicyID.String(id))
+	defer call.end(&err)
+
+	_, err = routeDeletePolicy.call(client, pathParams{"flavor": string(flavor), "id": id}, nil, nil)
+	return err
 }
 
 // UpsertOryAccessControlRole update or insert a ORY Access Control Role.
@@ -265,38 +370,15 @@ func (client *Client) DeleteOryAccessControlPolicy(flavor Flavor, id string) err
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#upsert-an-ory-access-control-policy-role
-func (client *Client) UpsertOryAccessControlRole(flavor Flavor, request *UpsertORYAccessRoleRequest) (*UpsertORYAccessRoleResponseOK, error) {
-	buf := bytes.NewBuffer(nil)
-	enc := json.NewEncoder(buf)
-	err := enc.Encode(request.Role)
-	if err != nil {
+func (client *Client) UpsertOryAccessControlRole(flavor Flavor, request *UpsertORYAccessRoleRequest, opts ...CallOption) (_ *UpsertORYAccessRoleResponseOK, err error) {
+	if err = validateUpsertRole(flavor, request); err != nil {
 		return nil, err
 	}
 
-	response, err := client.client.Put(client._url+"/engines/acp/ory/"+string(flavor)+"/roles", buf, nil)
-	if err != nil {
-		return nil, err
-	}
+	client, call := client.start("UpsertOryAccessControlRole", flavor, opts, AttributeRoleID.String(request.Role.ID))
+	defer call.end(&err)
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		r := &UpsertORYAccessRoleResponseOK{}
-		err := json.NewDecoder(response.Body).Decode(&r.Role)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
-	case http.StatusInternalServerError:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return nil, r
-	default:
-		return nil, &UnexpectedResponse{Response: response}
-	}
+	return routeUpsertRole.call(client, pathParams{"flavor": string(flavor)}, nil, request.Role)
 }
 
 // GetOryAccessControlRole return a ORY Access Control Role by ID.
@@ -307,33 +389,15 @@ func (client *Client) UpsertOryAccessControlRole(flavor Flavor, request *UpsertO
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#get-an-ory-access-control-policy-role
-func (client *Client) GetOryAccessControlRole(flavor Flavor, id string) (*GetORYAccessRoleResponseOK, error) {
-	response, err := client.client.Get(client._url+"/engines/acp/ory/"+string(flavor)+"/roles/"+id, nil)
-	if err != nil {
+func (client *Client) GetOryAccessControlRole(flavor Flavor, id string, opts ...CallOption) (_ *GetORYAccessRoleResponseOK, err error) {
+	if err = validateID("GetOryAccessControlRole", flavor, id); err != nil {
 		return nil, err
 	}
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		r := &GetORYAccessRoleResponseOK{}
-		err := json.NewDecoder(response.Body).Decode(&r.Role)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
-	case http.StatusNotFound:
-		return nil, ErrNotFound
-	case http.StatusInternalServerError:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return nil, r
-	default:
-		return nil, &UnexpectedResponse{Response: response}
-	}
+	client, call := client.start("GetOryAccessControlRole", flavor, opts, AttributeRoleID.String(id))
+	defer call.end(&err)
+
+	return routeGetRole.call(client, pathParams{"flavor": string(flavor), "id": id}, nil, nil)
 }
 
 // ListOryAccessControlRole list ORY Access Control Roles.
@@ -344,48 +408,15 @@ func (client *Client) GetOryAccessControlRole(flavor Flavor, id string) (*GetORY
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#list-ory-access-control-policy-roles
-func (client *Client) ListOryAccessControlRole(flavor Flavor, request *ListORYAccessRoleRequest) (*ListORYAccessRoleResponseOK, error) {
-	s := ""
-	if request.Limit > 0 {
-		s += fmt.Sprintf("limit=%d", request.Limit)
-	}
-	if request.Offset > 0 {
-		if s != "" {
-			s += "&"
-		}
-		s += fmt.Sprintf("offset=%d", request.Offset)
-	}
-
-	if s != "" {
-		s = "?" + s
-	}
-
-	response, err := client.client.Get(client._url+"/engines/acp/ory/"+string(flavor)+"/roles"+s, nil)
-	if err != nil {
+func (client *Client) ListOryAccessControlRole(flavor Flavor, request *ListORYAccessRoleRequest, opts ...CallOption) (_ *ListORYAccessRoleResponseOK, err error) {
+	if err = validateListRoles(flavor, request); err != nil {
 		return nil, err
 	}
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		r := &ListORYAccessRoleResponseOK{
-			Roles: make([]ORYAccessControlRole, 0, request.Limit),
-		}
-		err := json.NewDecoder(response.Body).Decode(&r.Roles)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
-	case http.StatusInternalServerError:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return nil, r
-	default:
-		return nil, &UnexpectedResponse{Response: response}
-	}
+	client, call := client.start("ListOryAccessControlRole", flavor, opts)
+	defer call.end(&err)
+
+	return routeListRoles.call(client, pathParams{"flavor": string(flavor)}, pageQuery(request.Limit, request.Offset), nil)
 }
 
 // DeleteOryAccessControlRole deletes an ORY Access Control Role.
@@ -396,26 +427,16 @@ func (client *Client) ListOryAccessControlRole(flavor Flavor, request *ListORYAc
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#delete-an-ory-access-control-policy-role
-func (client *Client) DeleteOryAccessControlRole(flavor Flavor, id string) error {
-	response, err := client.client.Delete(client._url+"/engines/acp/ory/"+string(flavor)+"/roles/"+id, nil)
-	if err != nil {
+func (client *Client) DeleteOryAccessControlRole(flavor Flavor, id string, opts ...CallOption) (err error) {
+	if err = validateID("DeleteOryAccessControlRole", flavor, id); err != nil {
 		return err
 	}
 
-	switch response.StatusCode {
-	case http.StatusNoContent:
-		return nil
-	case http.StatusInternalServerError:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return err
-		}
-		return r
-	default:
-		return &UnexpectedResponse{Response: response}
-	}
+	client, call := client.start("DeleteOryAccessControlRole", flavor, opts, AttributeRoleID.String(id))
+	defer call.end(&err)
+
+	_, err = routeDeleteRole.call(client, pathParams{"flavor": string(flavor), "id": id}, nil, nil)
+	return err
 }
 
 // AddMembersOryAccessControlRole deletes an ORY Access Control Policy.
@@ -426,38 +447,15 @@ func (client *Client) DeleteOryAccessControlRole(flavor Flavor, id string) error
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#add-a-member-to-an-ory-access-control-policy-role
-func (client *Client) AddMembersOryAccessControlRole(flavor Flavor, id string, request *AddMembersORYAccessRoleRequest) (*AddMembersORYAccessRoleResponseOK, error) {
-	buf := bytes.NewBuffer(nil)
-	enc := json.NewEncoder(buf)
-	err := enc.Encode(request)
-	if err != nil {
+func (client *Client) AddMembersOryAccessControlRole(flavor Flavor, id string, request *AddMembersORYAccessRoleRequest, opts ...CallOption) (_ *AddMembersORYAccessRoleResponseOK, err error) {
+	if err = validateAddMembers(flavor, id, request); err != nil {
 		return nil, err
 	}
 
-	response, err := client.client.Put(client._url+"/engines/acp/ory/"+string(flavor)+"/roles/"+id+"/members", buf, nil)
-	if err != nil {
-		return nil, err
-	}
+	client, call := client.start("AddMembersOryAccessControlRole", flavor, opts, AttributeRoleID.String(id))
+	defer call.end(&err)
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		r := &AddMembersORYAccessRoleResponseOK{}
-		err := json.NewDecoder(response.Body).Decode(&r.Role)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
-	case http.StatusInternalServerError:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return nil, r
-	default:
-		return nil, &UnexpectedResponse{Response: response}
-	}
+	return routeAddMembers.call(client, pathParams{"flavor": string(flavor), "id": id}, nil, request)
 }
 
 // RemoveMemberOryAccessControlRole removes a member from an ORY Access Control
@@ -469,26 +467,16 @@ func (client *Client) AddMembersOryAccessControlRole(flavor Flavor, id string, r
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#remove-a-member-from-an-ory-access-control-policy-role
-func (client *Client) RemoveMemberOryAccessControlRole(flavor Flavor, id, member string) error {
-	response, err := client.client.Delete(client._url+"/engines/acp/ory/"+string(flavor)+"/roles/"+id+"/members/"+member, nil)
-	if err != nil {
+func (client *Client) RemoveMemberOryAccessControlRole(flavor Flavor, id, member string, opts ...CallOption) (err error) {
+	if err = validateRemoveMember(flavor, id, member); err != nil {
 		return err
 	}
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		return nil
-	case http.StatusInternalServerError:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return err
-		}
-		return r
-	default:
-		return &UnexpectedResponse{Response: response}
-	}
+	client, call := client.start("RemoveMemberOryAccessControlRole", flavor, opts, AttributeRoleID.String(id), client.member(member))
+	defer call.end(&err)
+
+	_, err = routeRemoveMember.call(client, pathParams{"flavor": string(flavor), "id": id, "member": member}, nil, nil)
+	return err
 }
 
 // HealthAlive returns a 200 status code when the HTTP server is up running.
@@ -499,32 +487,11 @@ func (client *Client) RemoveMemberOryAccessControlRole(flavor Flavor, id, member
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#check-alive-status
-func (client *Client) HealthAlive() (*HealthAliveResponse, error) {
-	response, err := client.client.Get(client._url+"/health/alive", nil)
-	if err != nil {
-		return nil, err
-	}
+func (client *Client) HealthAlive(opts ...CallOption) (_ *HealthAliveResponse, err error) {
+	client, call := client.start("HealthAlive", "", opts)
+	defer call.end(&err)
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		r := &HealthAliveResponse{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
-	case http.StatusServiceUnavailable:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return nil, r
-	default:
-		return nil, &UnexpectedResponse{Response: response}
-	}
+	return routeHealthAlive.call(client, nil, nil, nil)
 }
 
 // HealthReadness returns a 200 status code when the HTTP server is up running
@@ -536,32 +503,11 @@ func (client *Client) HealthAlive() (*HealthAliveResponse, error) {
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#check-readiness-status
-func (client *Client) HealthReadness() (*HealthReadnessResponse, error) {
-	response, err := client.client.Get(client._url+"/health/ready", nil)
-	if err != nil {
-		return nil, err
-	}
+func (client *Client) HealthReadness(opts ...CallOption) (_ *HealthReadnessResponse, err error) {
+	client, call := client.start("HealthReadness", "", opts)
+	defer call.end(&err)
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		r := &HealthReadnessResponse{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
-	case http.StatusServiceUnavailable:
-		r := &ResponseError{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return nil, r
-	default:
-		return nil, &UnexpectedResponse{Response: response}
-	}
+	return routeHealthReady.call(client, nil, nil, nil)
 }
 
 // Version returns the service version typically notated using semantic
@@ -573,36 +519,19 @@ func (client *Client) HealthReadness() (*HealthReadnessResponse, error) {
 // ```
 //
 // See Also https://www.ory.sh/docs/keto/sdk/api#get-service-version
-func (client *Client) Version() (*VersionResponse, error) {
-	response, err := client.client.Get(client._url+"/version", nil)
-	if err != nil {
-		return nil, err
-	}
+func (client *Client) Version(opts ...CallOption) (_ *VersionResponse, err error) {
+	client, call := client.start("Version", "", opts)
+	defer call.end(&err)
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		r := &VersionResponse{}
-		dec := json.NewDecoder(response.Body)
-		err := dec.Decode(r)
-		if err != nil {
-			return nil, err
-		}
-		return r, nil
-	default:
-		return nil, &UnexpectedResponse{Response: response}
-	}
+	return routeVersion.call(client, nil, nil, nil)
 }
 
-func (client *Client) CheckVersion() error {
-	response, err := client.Version()
+func (client *Client) CheckVersion(opts ...CallOption) error {
+	response, err := client.Version(opts...)
 	if err != nil {
 		return err
 	}
-	v := response.Version
-	if strings.HasPrefix(v, "v") {
-		v = v[1:]
-	}
-	sv, err := semver.Make(v)
+	sv, err := parseServerVersion(response.Version)
 	if err != nil {
 		return err
 	}
@@ -611,3 +540,9 @@ func (client *Client) CheckVersion() error {
 	}
 	return errors.Wrap(ErrServerIncompatible, errors.Message("got "+response.Version))
 }
+
+// parseServerVersion parses the version reported by the server, which is
+// prefixed by `v`.
+func parseServerVersion(version string) (semver.Version, error) {
+	return semver.Make(strings.TrimPrefix(version, "v"))
+}
@@ -1,30 +1,59 @@
 package ketoclient
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/gojek/heimdall/hystrix"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
 type Option func(*Client)
 
 func New(opts ...Option) *Client {
-	c := &Client{}
+	c := &Client{
+		features:   &featureDetector{},
+		propagator: propagation.TraceContext{},
+		logLevels:  DefaultLogLevels,
+	}
 	for _, opt := range opts {
 		opt(c)
 	}
-	if c.url.Scheme == "" {
-		c.url.Scheme = "http"
+	if len(c.urls) == 0 {
+		c.urls = []url.URL{c.url}
+	}
+	urls := make([]string, len(c.urls))
+	for i := range c.urls {
+		if c.urls[i].Scheme == "" {
+			c.urls[i].Scheme = "http"
+		}
+		urls[i] = c.urls[i].String()
 	}
-	c._url = c.url.String()
-	if c.client == nil {
-		c.client = hystrix.NewClient()
+	c.url = c.urls[0]
+	c._url = urls[0]
+	c.endpoints = newEndpointPool(urls, c.balancing, c.ejectionCooldown)
+	c.initBreakers()
+	c.checkTLS()
+	c.metrics = newMetrics(c.breakers, c.metricsLabels)
+	if c.tracer == nil {
+		WithTracerProvider(otel.GetTracerProvider())(c)
 	}
 	return c
 }
 
 // WithHystrixClient creates an option that will define the `hystrix.Client`
 // when creating a new `Client`.
+//
+// The client is shared by all the categories that are not configured by
+// `WithBreaker`. It keeps its own transport, which the TLS options cannot
+// change: `Client.Validate` reports the conflict when both are used.
 func WithHystrixClient(client *hystrix.Client) Option {
 	return func(c *Client) {
 		c.client = client
@@ -35,5 +64,291 @@ func WithHystrixClient(client *hystrix.Client) Option {
 func WithURL(u *url.URL) Option {
 	return func(c *Client) {
 		c.url = *u
+		c.urls = nil
+	}
+}
+
+// WithURLs creates an option that defines several replicas of the Keto
+// server.
+//
+// Writes stick to the first healthy endpoint, while the other requests are
+// balanced among the healthy endpoints (see `WithBalancing`). Endpoints that
+// fail are ejected and get requests again after the cooldown defined by
+// `WithEjectionCooldown`, or when `CheckEndpoints` finds them ready.
+func WithURLs(urls ...*url.URL) Option {
+	return func(c *Client) {
+		c.urls = make([]url.URL, len(urls))
+		for i, u := range urls {
+			c.urls[i] = *u
+		}
+	}
+}
+
+// WithBalancing creates an option that defines how requests, but writes,
+// are balanced among the endpoints. The default is `RoundRobin`.
+func WithBalancing(balancing Balancing) Option {
+	return func(c *Client) {
+		c.balancing = balancing
+	}
+}
+
+// WithEjectionCooldown creates an option that defines how long a failing
+// endpoint stays out of the rotation. The default is
+// `DefaultEjectionCooldown`.
+func WithEjectionCooldown(cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.ejectionCooldown = cooldown
+	}
+}
+
+// WithRateLimit creates an option that limits the rate of the requests of a
+// category of operations using a token bucket that is refilled at `limit`
+// tokens per second and holds up to `burst` tokens.
+//
+// Requests wait for a token respecting the deadline of the context passed by
+// `Client.WithContext`.
+func WithRateLimit(category Category, limit float64, burst int) Option {
+	return func(c *Client) {
+		c.throttle(category).limiter = rate.NewLimiter(rate.Limit(limit), burst)
+	}
+}
+
+// WithMaxInFlight creates an option that caps the number of concurrent
+// requests of a category of operations.
+//
+// Requests wait for a slot respecting the deadline of the context passed by
+// `Client.WithContext`.
+func WithMaxInFlight(category Category, max int) Option {
+	return func(c *Client) {
+		c.throttle(category).slots = make(chan struct{}, max)
+	}
+}
+
+// WithBreaker creates an option that configures the circuit breaker of a
+// category of operations. The category gets its own hystrix commands, one
+// per endpoint, so failures of other categories or of other endpoints cannot
+// open its circuits.
+func WithBreaker(category Category, settings BreakerSettings) Option {
+	return func(c *Client) {
+		if c.breakerSettings == nil {
+			c.breakerSettings = make(map[Category]BreakerSettings)
+		}
+		c.breakerSettings[category] = settings
+	}
+}
+
+// WithBreakerPrefix creates an option that defines the prefix of the hystrix
+// command names. Hystrix settings are global, so clients for different Keto
+// servers should use different prefixes.
+func WithBreakerPrefix(prefix string) Option {
+	return func(c *Client) {
+		c.breakerPrefix = prefix
+	}
+}
+
+// WithBreakerListener creates an option that registers a function to be
+// called whenever a circuit breaker changes its state.
+func WithBreakerListener(listener BreakerListener) Option {
+	return func(c *Client) {
+		c.breakerListeners = append(c.breakerListeners, listener)
+	}
+}
+
+// WithFeatureDetection creates an option that makes the client probe the
+// server version before its first ACP request, failing the requests that the
+// server does not support with an `*UnsupportedError`.
+func WithFeatureDetection() Option {
+	return func(c *Client) {
+		c.features.auto = true
+	}
+}
+
+// WithStaleOnError creates an option that makes `AllowedOryAccessControlPolicy`
+// serve the last known decision for the same request, marked as stale, when
+// the server is down or cannot be reached: transport failures, open circuits,
+// throttled requests, 5xx responses and calls whose `CallTimeout` expired. Without a recent decision, the
+// configured default is applied. The other failures, a 401 for instance, are
+// returned as they are.
+func WithStaleOnError(config StaleOnError) Option {
+	return func(c *Client) {
+		if config.Store == nil {
+			config.Store = NewMemoryDecisionStore(DefaultDecisionStoreSize)
+		}
+		c.stale = &staleFallback{StaleOnError: config}
+	}
+}
+
+// WithCheckDeduplication creates an option that collapses identical
+// concurrent authorization checks (same flavor, subject, action, resource and
+// context) into a single request whose result is shared.
+//
+// The shared request does not end with the context, nor with the call
+// options, of the check that started it: it is limited by the timeout of the
+// client, the one of `WithCallOptions` or of the `CategoryCheck` requests.
+// Each check stops waiting for it when its own context is done.
+func WithCheckDeduplication() Option {
+	return func(c *Client) {
+		c.dedup = &dedup{}
+	}
+}
+
+// WithTracerProvider creates an option that defines the OpenTelemetry
+// provider of the spans of the client operations. The default is the global
+// provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *Client) {
+		c.tracer = provider.Tracer(instrumentationName)
+	}
+}
+
+// WithPropagator creates an option that defines how the trace context is
+// injected into the headers of the requests. The default is the W3C trace
+// context (`traceparent` and `tracestate` headers).
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *Client) {
+		c.propagator = propagator
+	}
+}
+
+// WithSubjectRedactor creates an option that defines how the subjects are
+// redacted before being recorded in the spans and in the logs. The default is
+// `RedactAll`.
+func WithSubjectRedactor(redactor Redactor) Option {
+	return func(c *Client) {
+		c.subjectRedactor = redactor
+	}
+}
+
+// WithMetricsLabels creates an option that adds constant labels to the
+// metrics of the client (see `Client.Collector`), so several clients can be
+// registered in the same registry.
+func WithMetricsLabels(labels prometheus.Labels) Option {
+	return func(c *Client) {
+		c.metricsLabels = labels
+	}
+}
+
+// WithResourceRedactor creates an option that defines how the resources of
+// the checks are redacted before being recorded in the spans and in the logs.
+// The default is `RedactNone`.
+func WithResourceRedactor(redactor Redactor) Option {
+	return func(c *Client) {
+		c.resourceRedactor = redactor
+	}
+}
+
+// WithContextRedactor creates an option that defines how the `Context` of the
+// checks is redacted before being logged. The default is
+// `RedactContextValues`.
+func WithContextRedactor(redactor ContextRedactor) Option {
+	return func(c *Client) {
+		c.contextRedactor = redactor
+	}
+}
+
+// WithLogger creates an option that makes the client log its requests,
+// responses and failures, at the levels defined by `WithLogLevels`. A
+// `*slog.Logger` can be used directly.
+func WithLogger(logger Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithLogLevels creates an option that defines the levels of the messages
+// logged by the client. The default is `DefaultLogLevels`.
+func WithLogLevels(levels LogLevels) Option {
+	return func(c *Client) {
+		c.logLevels = levels
+	}
+}
+
+// WithBasicAuth creates an option that authenticates every request with the
+// HTTP basic authentication. It replaces any bearer token.
+func WithBasicAuth(username, password string) Option {
+	return func(c *Client) {
+		c.credentials = &basicAuth{username: username, password: password}
+	}
+}
+
+// WithBearerToken creates an option that sends the same bearer token with
+// every request. It replaces any basic authentication.
+func WithBearerToken(token string) Option {
+	return WithTokenSource(StaticToken(token))
+}
+
+// WithTokenSource creates an option that sends a bearer token supplied by the
+// source with every request, failing the request when the source fails. It
+// replaces any basic authentication.
+//
+// See `NewRefreshingTokenSource` for tokens that expire.
+func WithTokenSource(source TokenSource) Option {
+	return func(c *Client) {
+		c.credentials = &bearerAuth{source: source}
+	}
+}
+
+// WithTLSConfig creates an option that defines the TLS configuration of the
+// connections to the Keto endpoints. The configuration is copied, replacing
+// the one built by `WithClientCertificate` and `WithRootCAs` given before.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = config.Clone()
+	}
+}
+
+// WithClientCertificate creates an option that presents the certificate to
+// the Keto endpoints, for mutual TLS.
+func WithClientCertificate(certificate tls.Certificate) Option {
+	return func(c *Client) {
+		config := c.ensureTLSConfig()
+		config.Certificates = append(config.Certificates, certificate)
+	}
+}
+
+// WithRootCAs creates an option that defines the certificate authorities
+// trusted to verify the Keto endpoints, instead of the ones of the system.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(c *Client) {
+		c.ensureTLSConfig().RootCAs = pool
+	}
+}
+
+// WithTransport creates an option that sends the requests through the
+// transport, a recording one for instance (see the `ketofixture` package).
+// The TLS options apply to an `*http.Transport`, which is cloned. Other
+// transports are in charge of their connections: `Client.Validate` reports
+// the conflict when they are combined with the TLS options.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpTransport = transport
+	}
+}
+
+// WithInterceptors creates an option that adds interceptors around the
+// requests of all the operations. Interceptors run in the order they are
+// given, after the ones added before.
+//
+// Deduplicated checks (see `WithCheckDeduplication`) go through the
+// interceptors once, for the single request they share.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// WithCallOptions creates an option that applies the call options to every
+// call of the client. The options given to a call are applied after them.
+func WithCallOptions(opts ...CallOption) Option {
+	return func(c *Client) {
+		c.callOptions = append(c.callOptions, opts...)
+	}
+}
+
+// WithDefaultFlavor creates an option that defines the flavor returned by
+// `DefaultFlavor`. The default is `Exact`.
+func WithDefaultFlavor(flavor Flavor) Option {
+	return func(c *Client) {
+		c.defaultFlavor = flavor
 	}
 }
@@ -7,16 +7,35 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/lab259/errors"
 	ketoclient "github.com/lab259/ory-keto-client"
+	"github.com/lab259/ory-keto-client/ketofixture"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/ory/dockertest"
 )
 
-var ketoServicePort string
+var (
+	ketoServicePort string
+
+	// fixtures sends the requests of the clients of the running spec to Keto,
+	// recording them, or replays them (see `ketofixture.ModeFromEnv`).
+	fixtures *ketofixture.Transport
+)
+
+var nonAlphanumeric = regexp.MustCompile("[^a-z0-9]+")
+
+// fixturePath returns the golden file of the running spec.
+func fixturePath() string {
+	name := strings.ToLower(CurrentGinkgoTestDescription().FullTestText)
+	name = strings.Trim(nonAlphanumeric.ReplaceAllString(name, "-"), "-")
+	return filepath.Join("testdata", "fixtures", name+".json")
+}
 
 func ketoURL() *url.URL {
 	u, err := url.Parse(fmt.Sprintf("http://localhost:%s", ketoServicePort))
@@ -29,6 +48,7 @@ func ketoURL() *url.URL {
 func ketoClient() *ketoclient.Client {
 	return ketoclient.New(
 		ketoclient.WithURL(ketoURL()),
+		ketoclient.WithTransport(fixtures),
 	)
 }
 
@@ -41,6 +61,15 @@ var _ = Describe("Client", func() {
 	BeforeEach(func() {
 		var err error
 
+		mode := ketofixture.ModeFromEnv()
+		resource, fixtures = nil, nil
+		if mode == ketofixture.Replay {
+			ketoServicePort = "4466"
+			fixtures, err = ketofixture.New(fixturePath(), mode, nil)
+			Expect(err).ToNot(HaveOccurred())
+			return
+		}
+
 		logger := log.New(GinkgoWriter, "[keto initialization] ", 0)
 
 		logger.Println("Starting pool")
@@ -71,9 +100,20 @@ var _ = Describe("Client", func() {
 			logger.Println("Keto service: ", response.Status, ":", string(data))
 			return nil
 		})).To(Succeed())
+
+		fixtures, err = ketofixture.New(fixturePath(), mode, nil)
+		Expect(err).ToNot(HaveOccurred())
 	})
 
 	AfterEach(func() {
+		if fixtures == nil {
+			return
+		}
+		if resource == nil {
+			Expect(fixtures.Unused()).To(BeEmpty())
+			return
+		}
+		Expect(fixtures.Save()).To(Succeed())
 		Expect(pool.Purge(resource)).To(Succeed())
 	})
 
@@ -620,20 +660,23 @@ var _ = Describe("Client", func() {
 
 			Expect(client.CheckVersion()).To(Succeed())
 		})
+	})
+})
 
-		It("should fail checking the server compatibility with an old server", func() {
-			ts := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, req *http.Request) {
-				response.Write([]byte(`{"version":"v0.2.1"}`))
-			}))
-			defer ts.Close()
+// The specs below run against their own server, without Keto.
+var _ = Describe("CheckVersion", func() {
+	It("should fail checking the server compatibility with an old server", func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, req *http.Request) {
+			response.Write([]byte(`{"version":"v0.2.1"}`))
+		}))
+		defer ts.Close()
 
-			u, err := url.Parse(ts.URL)
-			Expect(err).ToNot(HaveOccurred())
-			client := ketoclient.New(ketoclient.WithURL(u))
+		u, err := url.Parse(ts.URL)
+		Expect(err).ToNot(HaveOccurred())
+		client := ketoclient.New(ketoclient.WithURL(u))
 
-			err = client.CheckVersion()
-			Expect(errors.Is(err, ketoclient.ErrServerIncompatible)).To(BeTrue())
-			Expect(err.Error()).To(HavePrefix("got v0.2.1"))
-		})
+		err = client.CheckVersion()
+		Expect(errors.Is(err, ketoclient.ErrServerIncompatible)).To(BeTrue())
+		Expect(err.Error()).To(HavePrefix("got v0.2.1"))
 	})
 })
@@ -0,0 +1,121 @@
+package ketoclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// condition is the serialized format of a policy condition.
+//
+// See Also https://www.ory.sh/docs/keto/engines/acp-ory#conditions
+type condition struct {
+	Type    string                 `json:"type"`
+	Options map[string]interface{} `json:"options"`
+}
+
+// evaluateConditions checks the conditions of a policy against the request.
+// When a condition is not fulfilled, it returns a description of the failure
+// prefixed by the condition key.
+func evaluateConditions(conditions interface{}, request *AllowedORYAccessControlPolicyRequest) (bool, string, error) {
+	if !hasConditions(conditions) {
+		return true, "", nil
+	}
+
+	parsed := make(map[string]condition)
+	if err := normalizeJSON(conditions, &parsed); err != nil {
+		return false, "", err
+	}
+	values := make(map[string]interface{})
+	if request.Context != nil {
+		if err := normalizeJSON(request.Context, &values); err != nil {
+			return false, "", err
+		}
+	}
+
+	keys := make([]string, 0, len(parsed))
+	for key := range parsed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		ok, reason := parsed[key].fulfills(request, values[key])
+		if !ok {
+			return false, key + ": " + reason, nil
+		}
+	}
+	return true, "", nil
+}
+
+// fulfills implements the conditions supported by Keto.
+func (c condition) fulfills(request *AllowedORYAccessControlPolicyRequest, value interface{}) (bool, string) {
+	switch c.Type {
+	case "StringEqualCondition":
+		s, ok := value.(string)
+		if !ok || s != c.Options["equals"] {
+			return false, fmt.Sprintf("%v is not equal to %v", value, c.Options["equals"])
+		}
+	case "StringMatchCondition":
+		s, ok := value.(string)
+		pattern, _ := c.Options["matches"].(string)
+		matched, err := regexp.MatchString(pattern, s)
+		if !ok || err != nil || !matched {
+			return false, fmt.Sprintf("%v does not match %s", value, pattern)
+		}
+	case "EqualsSubjectCondition":
+		s, ok := value.(string)
+		if !ok || s != request.Subject {
+			return false, fmt.Sprintf("%v is not equal to the subject", value)
+		}
+	case "CIDRCondition":
+		s, _ := value.(string)
+		cidr, _ := c.Options["cidr"].(string)
+		_, network, err := net.ParseCIDR(cidr)
+		ip := net.ParseIP(s)
+		if err != nil || ip == nil || !network.Contains(ip) {
+			return false, fmt.Sprintf("%v is not in %s", value, cidr)
+		}
+	case "BooleanCondition":
+		b, ok := value.(bool)
+		if !ok || b != c.Options["value"] {
+			return false, fmt.Sprintf("%v is not %v", value, c.Options["value"])
+		}
+	case "StringPairsEqualCondition":
+		pairs, ok := value.([]interface{})
+		if !ok {
+			return false, fmt.Sprintf("%v is not a list of pairs", value)
+		}
+		for _, pair := range pairs {
+			p, ok := pair.([]interface{})
+			if !ok || len(p) != 2 || p[0] != p[1] {
+				return false, fmt.Sprintf("%v is not an equal pair", pair)
+			}
+		}
+	case "ResourceContainsCondition":
+		s, ok := value.(string)
+		resource := request.Resource
+		if delimiter, _ := c.Options["delimiter"].(string); delimiter != "" {
+			s = delimiter + s + delimiter
+			resource = delimiter + resource + delimiter
+		}
+		if !ok || !strings.Contains(resource, s) {
+			return false, fmt.Sprintf("the resource does not contain %v", value)
+		}
+	default:
+		return false, "unsupported condition type " + c.Type
+	}
+	return true, ""
+}
+
+// normalizeJSON converts a value into its generic JSON representation.
+func normalizeJSON(value interface{}, target interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
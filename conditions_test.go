@@ -0,0 +1,50 @@
+package ketoclient
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("evaluateConditions", func() {
+	table.DescribeTable("should evaluate the conditions supported by Keto",
+		func(conditionType string, options map[string]interface{}, value interface{}, expected bool) {
+			ok, detail, err := evaluateConditions(map[string]interface{}{
+				"key": map[string]interface{}{
+					"type":    conditionType,
+					"options": options,
+				},
+			}, &AllowedORYAccessControlPolicyRequest{
+				Subject:  "user:snake-eyes",
+				Resource: "blog1:post:33",
+				Context:  map[string]interface{}{"key": value},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(Equal(expected))
+			if !expected {
+				Expect(detail).To(HavePrefix("key: "))
+			}
+		},
+		table.Entry("string equal", "StringEqualCondition", map[string]interface{}{"equals": "a"}, "a", true),
+		table.Entry("string not equal", "StringEqualCondition", map[string]interface{}{"equals": "a"}, "b", false),
+		table.Entry("string match", "StringMatchCondition", map[string]interface{}{"matches": "^a+$"}, "aaa", true),
+		table.Entry("string mismatch", "StringMatchCondition", map[string]interface{}{"matches": "^a+$"}, "aab", false),
+		table.Entry("equals subject", "EqualsSubjectCondition", nil, "user:snake-eyes", true),
+		table.Entry("not equals subject", "EqualsSubjectCondition", nil, "user:scarlet", false),
+		table.Entry("inside CIDR", "CIDRCondition", map[string]interface{}{"cidr": "10.0.0.0/8"}, "10.1.2.3", true),
+		table.Entry("outside CIDR", "CIDRCondition", map[string]interface{}{"cidr": "10.0.0.0/8"}, "192.168.0.1", false),
+		table.Entry("boolean", "BooleanCondition", map[string]interface{}{"value": true}, true, true),
+		table.Entry("boolean mismatch", "BooleanCondition", map[string]interface{}{"value": true}, false, false),
+		table.Entry("string pairs equal", "StringPairsEqualCondition", nil, [][]string{{"a", "a"}}, true),
+		table.Entry("string pairs not equal", "StringPairsEqualCondition", nil, [][]string{{"a", "b"}}, false),
+		table.Entry("resource contains", "ResourceContainsCondition", map[string]interface{}{"delimiter": ":"}, "post", true),
+		table.Entry("resource does not contain", "ResourceContainsCondition", map[string]interface{}{"delimiter": ":"}, "pos", false),
+		table.Entry("unsupported condition", "UnknownCondition", nil, "a", false),
+	)
+
+	It("should accept a policy without conditions", func() {
+		ok, _, err := evaluateConditions(map[string]interface{}{}, &AllowedORYAccessControlPolicyRequest{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+	})
+})
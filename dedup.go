@@ -0,0 +1,116 @@
+package ketoclient
+
+import (
+	"context"
+	"sync/atomic"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DedupStats reports how many authorization checks were collapsed into a
+// request of another identical check.
+type DedupStats struct {
+	// Checks is the number of checks made.
+	Checks int64
+
+	// Deduplicated is the number of checks that shared the result of another
+	// check instead of making their own request.
+	Deduplicated int64
+}
+
+// Rate returns the fraction of the checks that were deduplicated.
+func (s DedupStats) Rate() float64 {
+	if s.Checks == 0 {
+		return 0
+	}
+	return float64(s.Deduplicated) / float64(s.Checks)
+}
+
+// dedup collapses identical concurrent checks into a single request.
+type dedup struct {
+	// Accessed atomically, kept first for 64-bit alignment.
+	checks   int64
+	requests int64
+
+	group singleflight.Group
+}
+
+// do calls fn once for all the concurrent calls with the same key, sharing
+// its result. Each caller gets its own copy of the response.
+//
+// The shared request runs on a context that no caller can cancel (see
+// `shared`). Each caller stops waiting when its own context is done.
+func (d *dedup) do(client *Client, key string, fn func() (*AllowedORYAccessControlPolicyResponse, error)) (*AllowedORYAccessControlPolicyResponse, error) {
+	atomic.AddInt64(&d.checks, 1)
+	ch := d.group.DoChan(key, func() (interface{}, error) {
+		atomic.AddInt64(&d.requests, 1)
+		return fn()
+	})
+
+	ctx := client.context()
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		response := *result.Val.(*AllowedORYAccessControlPolicyResponse)
+		return &response, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// shared returns a copy of the client for the request shared by the
+// deduplicated checks, started by the first caller.
+//
+// Its context keeps the values of the context of the first caller, the span
+// and the logging attributes of the call included, but not its cancellation.
+// The call options of the first caller are replaced by the ones of the client,
+// and the request is limited by the timeout of the client instead: the one
+// given by `WithCallOptions` or, when there is none, the timeout of the
+// requests of `CategoryCheck`.
+func (d *dedup) shared(client *Client) (*Client, context.CancelFunc) {
+	options := newCallOptions(client.callOptions, nil)
+	timeout := options.timeout
+	if timeout <= 0 {
+		timeout = client.breakerSettings[CategoryCheck].httpTimeout()
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(client.context()), timeout)
+	c := callFromContext(ctx)
+	if c == nil {
+		return client.WithContext(ctx), cancel
+	}
+	shared := &call{
+		client:       c.client,
+		operation:    c.operation,
+		flavor:       c.flavor,
+		attrs:        c.attrs,
+		span:         c.span,
+		startedAt:    c.startedAt,
+		options:      options,
+		checkContext: c.checkContext,
+	}
+	shared.ctx = context.WithValue(ctx, callKey{}, shared)
+	return client.WithContext(shared.ctx), func() {
+		cancel()
+		atomic.StoreInt64(&c.status, atomic.LoadInt64(&shared.status))
+	}
+}
+
+func (d *dedup) stats() DedupStats {
+	if d == nil {
+		return DedupStats{}
+	}
+	checks := atomic.LoadInt64(&d.checks)
+	requests := atomic.LoadInt64(&d.requests)
+	return DedupStats{
+		Checks:       checks,
+		Deduplicated: checks - requests,
+	}
+}
+
+// DedupStats returns the deduplication statistics of the authorization
+// checks. It is always zero unless `WithCheckDeduplication` is used.
+func (client *Client) DedupStats() DedupStats {
+	return client.dedup.stats()
+}
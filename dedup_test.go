@@ -0,0 +1,117 @@
+package ketoclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithCheckDeduplication", func() {
+	var (
+		server   *httptest.Server
+		client   *ketoclient.Client
+		requests int64
+		release  chan struct{}
+	)
+
+	BeforeEach(func() {
+		atomic.StoreInt64(&requests, 0)
+		release = make(chan struct{})
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&requests, 1)
+			<-release
+			writeJSON(w, http.StatusOK, &ketoclient.AllowedORYAccessControlPolicyResponse{Allowed: true})
+		}))
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		client = ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithCheckDeduplication())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	check := func(wg *sync.WaitGroup, resource string) {
+		wg.Add(1)
+		go func() {
+			defer GinkgoRecover()
+			defer wg.Done()
+			response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+				Subject:  "user:snake-eyes",
+				Action:   "delete",
+				Resource: resource,
+				Context:  map[string]interface{}{"remoteIP": "10.0.0.1"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Allowed).To(BeTrue())
+		}()
+	}
+
+	It("should collapse identical concurrent checks", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			check(&wg, "blog1:post:33")
+		}
+		Eventually(func() int64 {
+			return client.DedupStats().Checks
+		}).Should(Equal(int64(10)))
+		close(release)
+		wg.Wait()
+
+		Expect(atomic.LoadInt64(&requests)).To(Equal(int64(1)))
+		stats := client.DedupStats()
+		Expect(stats.Deduplicated).To(Equal(int64(9)))
+		Expect(stats.Rate()).To(Equal(0.9))
+	})
+
+	It("should not collapse different checks", func() {
+		var wg sync.WaitGroup
+		check(&wg, "blog1:post:33")
+		check(&wg, "blog1:post:34")
+		Eventually(func() int64 {
+			return atomic.LoadInt64(&requests)
+		}).Should(Equal(int64(2)))
+		close(release)
+		wg.Wait()
+
+		Expect(client.DedupStats()).To(Equal(ketoclient.DedupStats{Checks: 2}))
+	})
+
+	It("should not fail the other checks when the first one gives up", func() {
+		request := &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: "blog1:post:33",
+		}
+		_, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request, ketoclient.CallTimeout(20*time.Millisecond))
+		Expect(err).To(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		done := make(chan error, 1)
+		go func() {
+			defer GinkgoRecover()
+			response, err := client.WithContext(ctx).AllowedOryAccessControlPolicy(ketoclient.Exact, request)
+			if err == nil {
+				Expect(response.Allowed).To(BeTrue())
+			}
+			done <- err
+		}()
+		Eventually(func() int64 {
+			return client.DedupStats().Deduplicated
+		}).Should(Equal(int64(1)))
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+
+		Expect(<-done).ToNot(HaveOccurred())
+		Expect(atomic.LoadInt64(&requests)).To(Equal(int64(1)))
+	})
+})
@@ -0,0 +1,235 @@
+package ketoclient
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	hystrixgo "github.com/afex/hystrix-go/hystrix"
+	"github.com/lab259/errors/v2"
+)
+
+// DefaultEjectionCooldown is how long an ejected endpoint is kept out of the
+// rotation before it gets a request again.
+const DefaultEjectionCooldown = 30 * time.Second
+
+// Balancing is the strategy used to pick an endpoint for the requests that
+// are not writes.
+type Balancing string
+
+const (
+	// RoundRobin cycles through the healthy endpoints.
+	RoundRobin Balancing = "round-robin"
+
+	// LeastInFlight picks the healthy endpoint with the fewest requests being
+	// sent.
+	LeastInFlight Balancing = "least-in-flight"
+)
+
+// EndpointStatus describes an endpoint of the client.
+type EndpointStatus struct {
+	URL      string `json:"url"`
+	Healthy  bool   `json:"healthy"`
+	Primary  bool   `json:"primary"`
+	InFlight int64  `json:"in_flight"`
+}
+
+type endpoint struct {
+	// Accessed atomically, kept first for 64-bit alignment.
+	inFlight int64
+
+	// ejectedAt is the unix time in nanoseconds of the ejection. Zero means
+	// that the endpoint is healthy.
+	ejectedAt int64
+
+	// index is the position of the endpoint in the pool.
+	index int
+	url   string
+}
+
+func (e *endpoint) healthy() bool {
+	return atomic.LoadInt64(&e.ejectedAt) == 0
+}
+
+// endpointPool picks the endpoint of each request.
+//
+// Writes stick to a primary endpoint, which only changes when it is ejected.
+// The other requests are balanced among the healthy endpoints. When every
+// endpoint is ejected, all of them are used.
+type endpointPool struct {
+	endpoints []*endpoint
+	balancing Balancing
+	cooldown  time.Duration
+
+	// next is the round robin counter, accessed atomically.
+	next uint64
+
+	mu      sync.Mutex
+	primary int
+}
+
+func newEndpointPool(urls []string, balancing Balancing, cooldown time.Duration) *endpointPool {
+	if balancing == "" {
+		balancing = RoundRobin
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultEjectionCooldown
+	}
+	p := &endpointPool{
+		endpoints: make([]*endpoint, len(urls)),
+		balancing: balancing,
+		cooldown:  cooldown,
+	}
+	for i, u := range urls {
+		p.endpoints[i] = &endpoint{index: i, url: u}
+	}
+	return p
+}
+
+// available reports if the endpoint can get requests: it is healthy or its
+// ejection cooled down.
+func (p *endpointPool) available(e *endpoint, now time.Time) bool {
+	ejectedAt := atomic.LoadInt64(&e.ejectedAt)
+	return ejectedAt == 0 || now.Sub(time.Unix(0, ejectedAt)) >= p.cooldown
+}
+
+func (p *endpointPool) pick(category Category) *endpoint {
+	if len(p.endpoints) == 1 {
+		return p.endpoints[0]
+	}
+	if category == CategoryWrite {
+		return p.pickPrimary()
+	}
+
+	now := time.Now()
+	candidates := make([]*endpoint, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		if p.available(e, now) {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = p.endpoints
+	}
+
+	if p.balancing == LeastInFlight {
+		best := candidates[0]
+		for _, e := range candidates[1:] {
+			if atomic.LoadInt64(&e.inFlight) < atomic.LoadInt64(&best.inFlight) {
+				best = e
+			}
+		}
+		return best
+	}
+	return candidates[atomic.AddUint64(&p.next, 1)%uint64(len(candidates))]
+}
+
+// pickPrimary returns the primary endpoint, moving to the next available one
+// when it is ejected.
+func (p *endpointPool) pickPrimary() *endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	primary := p.endpoints[p.primary]
+	if primary.healthy() {
+		return primary
+	}
+	for i := 1; i < len(p.endpoints); i++ {
+		idx := (p.primary + i) % len(p.endpoints)
+		if p.endpoints[idx].healthy() {
+			p.primary = idx
+			return p.endpoints[idx]
+		}
+	}
+	return primary
+}
+
+func (p *endpointPool) eject(e *endpoint) {
+	atomic.CompareAndSwapInt64(&e.ejectedAt, 0, time.Now().UnixNano())
+}
+
+func (p *endpointPool) reinstate(e *endpoint) {
+	atomic.StoreInt64(&e.ejectedAt, 0)
+}
+
+// observe ejects the endpoint on transport failures, its open circuit
+// included, and reinstates it on success.
+func (p *endpointPool) observe(ctx context.Context, e *endpoint, err error) {
+	if err == nil {
+		p.reinstate(e)
+		return
+	}
+	if ctx.Err() != nil || !endpointFailure(err) {
+		return
+	}
+	p.eject(e)
+}
+
+// endpointFailure reports whether the error tells that the endpoint is
+// failing. As every endpoint has its own circuit, an open circuit or a
+// timeout does. Too many concurrent requests do not.
+func endpointFailure(err error) bool {
+	return !errors.Is(err, hystrixgo.ErrMaxConcurrency)
+}
+
+func (p *endpointPool) status() []EndpointStatus {
+	p.mu.Lock()
+	primary := p.primary
+	p.mu.Unlock()
+
+	r := make([]EndpointStatus, len(p.endpoints))
+	for i, e := range p.endpoints {
+		r[i] = EndpointStatus{
+			URL:      e.url,
+			Healthy:  e.healthy(),
+			Primary:  i == primary,
+			InFlight: atomic.LoadInt64(&e.inFlight),
+		}
+	}
+	return r
+}
+
+// Endpoints returns the status of the endpoints of the client.
+func (client *Client) Endpoints() []EndpointStatus {
+	return client.endpoints.status()
+}
+
+// CheckEndpoints calls `HealthReadness` on every endpoint, ejecting the ones
+// that are not ready and reinstating the ones that are.
+func (client *Client) CheckEndpoints() []EndpointStatus {
+	var wg sync.WaitGroup
+	for _, e := range client.endpoints.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			c := *client
+			c.target = e
+			if _, err := c.HealthReadness(); err != nil {
+				if endpointFailure(err) {
+					client.endpoints.eject(e)
+				}
+				return
+			}
+			client.endpoints.reinstate(e)
+		}(e)
+	}
+	wg.Wait()
+	return client.Endpoints()
+}
+
+// MonitorEndpoints calls `CheckEndpoints` at every interval until the context
+// is done.
+func (client *Client) MonitorEndpoints(ctx context.Context, interval time.Duration) {
+	c := client.WithContext(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.CheckEndpoints()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
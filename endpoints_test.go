@@ -0,0 +1,198 @@
+package ketoclient_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type replica struct {
+	server   *httptest.Server
+	requests int64
+	ready    int32
+	block    chan struct{}
+}
+
+func newReplica() *replica {
+	r := &replica{ready: 1}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&r.requests, 1)
+		if r.block != nil && req.URL.Path != "/health/ready" {
+			<-r.block
+		}
+		switch {
+		case req.URL.Path == "/health/ready" && atomic.LoadInt32(&r.ready) == 0:
+			writeJSON(w, http.StatusServiceUnavailable, &ketoclient.ResponseError{Code: 503})
+		case req.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		}
+	}))
+	return r
+}
+
+func (r *replica) URL() *url.URL {
+	u, err := url.Parse(r.server.URL)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func (r *replica) Requests() int64 {
+	return atomic.LoadInt64(&r.requests)
+}
+
+var _ = Describe("Endpoints", func() {
+	var replicas []*replica
+
+	BeforeEach(func() {
+		replicas = []*replica{newReplica(), newReplica(), newReplica()}
+	})
+
+	AfterEach(func() {
+		for _, r := range replicas {
+			r.server.Close()
+		}
+	})
+
+	newClient := func(opts ...ketoclient.Option) *ketoclient.Client {
+		return ketoclient.New(append([]ketoclient.Option{
+			ketoclient.WithURLs(replicas[0].URL(), replicas[1].URL(), replicas[2].URL()),
+			withUniqueBreakerPrefix(),
+		}, opts...)...)
+	}
+
+	It("should balance the reads in round robin", func() {
+		client := newClient()
+		for i := 0; i < 9; i++ {
+			_, err := client.HealthAlive()
+			Expect(err).ToNot(HaveOccurred())
+		}
+		for _, r := range replicas {
+			Expect(r.Requests()).To(Equal(int64(3)))
+		}
+	})
+
+	It("should send the reads to the endpoint with least requests in flight", func() {
+		replicas[0].block = make(chan struct{})
+		client := newClient(ketoclient.WithBalancing(ketoclient.LeastInFlight))
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer GinkgoRecover()
+			defer wg.Done()
+			_, err := client.HealthAlive()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		Eventually(replicas[0].Requests).Should(Equal(int64(1)))
+
+		for i := 0; i < 4; i++ {
+			_, err := client.HealthAlive()
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(replicas[0].Requests()).To(Equal(int64(1)))
+		Expect(replicas[1].Requests() + replicas[2].Requests()).To(Equal(int64(4)))
+
+		close(replicas[0].block)
+		wg.Wait()
+	})
+
+	It("should stick the writes to the primary endpoint", func() {
+		client := newClient()
+		for i := 0; i < 3; i++ {
+			Expect(client.DeleteOryAccessControlPolicy(ketoclient.Exact, "id1")).To(Succeed())
+		}
+		Expect(replicas[0].Requests()).To(Equal(int64(3)))
+		Expect(client.Endpoints()[0].Primary).To(BeTrue())
+
+		replicas[0].server.Close()
+		Expect(client.DeleteOryAccessControlPolicy(ketoclient.Exact, "id1")).ToNot(Succeed())
+		for i := 0; i < 3; i++ {
+			Expect(client.DeleteOryAccessControlPolicy(ketoclient.Exact, "id1")).To(Succeed())
+		}
+		Expect(replicas[1].Requests()).To(Equal(int64(3)))
+		Expect(client.Endpoints()[1].Primary).To(BeTrue())
+	})
+
+	It("should eject the endpoints that fail and reinstate them once ready", func() {
+		client := newClient()
+
+		atomic.StoreInt32(&replicas[1].ready, 0)
+		status := client.CheckEndpoints()
+		Expect(status[0].Healthy).To(BeTrue())
+		Expect(status[1].Healthy).To(BeFalse())
+		Expect(status[2].Healthy).To(BeTrue())
+
+		before := replicas[1].Requests()
+		for i := 0; i < 4; i++ {
+			_, err := client.HealthAlive()
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(replicas[1].Requests()).To(Equal(before))
+
+		atomic.StoreInt32(&replicas[1].ready, 1)
+		status = client.CheckEndpoints()
+		Expect(status[1].Healthy).To(BeTrue())
+	})
+
+	It("should keep the circuits of the healthy endpoints closed", func() {
+		client := newClient(ketoclient.WithBreaker(ketoclient.CategoryHealth, ketoclient.BreakerSettings{
+			RequestVolumeThreshold: 1,
+			ErrorPercentThreshold:  1,
+			SleepWindow:            time.Minute,
+		}))
+
+		replicas[1].server.Close()
+		for i := 0; i < 6; i++ {
+			_, _ = client.HealthAlive()
+		}
+		Expect(client.Endpoints()[1].Healthy).To(BeFalse())
+		Expect(client.BreakerState(ketoclient.CategoryHealth)).To(Equal(ketoclient.BreakerClosed))
+
+		for i := 0; i < 4; i++ {
+			_, err := client.HealthAlive()
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("should retry the requests on another endpoint", func() {
+		client := newClient()
+		backoff := ketoclient.Backoff{Initial: time.Millisecond, Multiplier: 1}
+
+		replicas[1].server.Close()
+		for i := 0; i < 3; i++ {
+			_, err := client.HealthAlive(ketoclient.CallRetries(1, backoff))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		replicas[0].server.Close()
+		Expect(client.DeleteOryAccessControlPolicy(ketoclient.Exact, "id1", ketoclient.CallRetries(1, backoff), ketoclient.CallIdempotencyKey("delete-id1"))).To(Succeed())
+		Expect(replicas[2].Requests()).To(BeNumerically(">=", 2))
+	})
+
+	It("should send requests to an ejected endpoint after the cooldown", func() {
+		client := newClient(ketoclient.WithEjectionCooldown(10 * time.Millisecond))
+
+		atomic.StoreInt32(&replicas[1].ready, 0)
+		Expect(client.CheckEndpoints()[1].Healthy).To(BeFalse())
+		time.Sleep(20 * time.Millisecond)
+
+		before := replicas[1].Requests()
+		for i := 0; i < 3; i++ {
+			_, err := client.HealthAlive()
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(replicas[1].Requests()).To(Equal(before + 1))
+		Expect(client.Endpoints()[1].Healthy).To(BeTrue())
+	})
+})
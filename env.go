@@ -0,0 +1,286 @@
+package ketoclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lab259/errors/v2"
+)
+
+// The environment variables read by `NewFromEnv`.
+const (
+	// EnvURL is the comma separated list of the URLs of the Keto replicas.
+	// It is required.
+	EnvURL = "KETO_URL"
+
+	// EnvBalancing is how the requests are balanced among the replicas:
+	// `round-robin` or `least-in-flight`.
+	EnvBalancing = "KETO_BALANCING"
+
+	// EnvTimeout limits the duration of every call, retries included
+	// (`CallTimeout`).
+	EnvTimeout = "KETO_TIMEOUT"
+
+	// EnvRetries is how many times the failed calls are retried
+	// (`CallRetries`).
+	EnvRetries = "KETO_RETRIES"
+
+	// EnvRetryBackoff is the wait before the first retry, doubled for each
+	// of the following retries up to `DefaultRetryBackoff.Max`. It goes with
+	// EnvRetries.
+	EnvRetryBackoff = "KETO_RETRY_BACKOFF"
+
+	// EnvBreakerTimeout, EnvBreakerMaxConcurrentRequests,
+	// EnvBreakerRequestVolumeThreshold, EnvBreakerSleepWindow and
+	// EnvBreakerErrorPercentThreshold configure the circuit breakers of all
+	// the categories (see `BreakerSettings`).
+	EnvBreakerTimeout                = "KETO_BREAKER_TIMEOUT"
+	EnvBreakerMaxConcurrentRequests  = "KETO_BREAKER_MAX_CONCURRENT_REQUESTS"
+	EnvBreakerRequestVolumeThreshold = "KETO_BREAKER_REQUEST_VOLUME_THRESHOLD"
+	EnvBreakerSleepWindow            = "KETO_BREAKER_SLEEP_WINDOW"
+	EnvBreakerErrorPercentThreshold  = "KETO_BREAKER_ERROR_PERCENT_THRESHOLD"
+
+	// EnvBearerToken is the token sent with every request
+	// (`WithBearerToken`).
+	EnvBearerToken = "KETO_BEARER_TOKEN"
+
+	// EnvBasicAuthUsername and EnvBasicAuthPassword are the credentials sent
+	// with every request (`WithBasicAuth`). They cannot be used with
+	// EnvBearerToken.
+	EnvBasicAuthUsername = "KETO_BASIC_AUTH_USERNAME"
+	EnvBasicAuthPassword = "KETO_BASIC_AUTH_PASSWORD"
+
+	// EnvFlavor is the flavor returned by `DefaultFlavor`.
+	EnvFlavor = "KETO_FLAVOR"
+
+	// EnvTLSCAFile is the PEM file of the certificate authorities trusted to
+	// verify the servers (`WithRootCAs`).
+	EnvTLSCAFile = "KETO_TLS_CA_FILE"
+
+	// EnvTLSCertFile and EnvTLSKeyFile are the PEM files of the client
+	// certificate and of its key (`WithClientCertificate`). They go
+	// together.
+	EnvTLSCertFile = "KETO_TLS_CERT_FILE"
+	EnvTLSKeyFile  = "KETO_TLS_KEY_FILE"
+)
+
+// ErrInvalidEnv is the reason of all `*EnvError`.
+var ErrInvalidEnv = errors.New("invalid environment")
+
+// EnvError is returned when environment variables are missing or malformed.
+// It lists all of them. The values of the credentials are never included.
+type EnvError struct {
+	Variables []FieldError
+}
+
+func (err *EnvError) Error() string {
+	variables := make([]string, len(err.Variables))
+	for i, variable := range err.Variables {
+		variables[i] = variable.String()
+	}
+	return "invalid environment: " + strings.Join(variables, ", ")
+}
+
+// Unwrap returns `ErrInvalidEnv`.
+func (err *EnvError) Unwrap() error {
+	return ErrInvalidEnv
+}
+
+// NewFromEnv creates a client configured by the `KETO_*` environment
+// variables (see `EnvURL` and the following constants). The options are
+// applied after the ones read from the environment. It fails when the
+// options conflict (see `Client.Validate`).
+func NewFromEnv(opts ...Option) (*Client, error) {
+	envOpts, err := OptionsFromEnv(os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	client := New(append(envOpts, opts...)...)
+	if err := client.Validate(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// OptionsFromEnv reads the options of the `KETO_*` environment variables
+// with the given lookup function, `os.LookupEnv` for instance.
+func OptionsFromEnv(lookup func(name string) (string, bool)) ([]Option, error) {
+	env := &envReader{lookup: lookup}
+	var opts []Option
+
+	if urls := env.urls(EnvURL); len(urls) > 0 {
+		opts = append(opts, WithURLs(urls...))
+	}
+	if balancing, ok := env.get(EnvBalancing); ok {
+		switch b := Balancing(balancing); b {
+		case RoundRobin, LeastInFlight:
+			opts = append(opts, WithBalancing(b))
+		default:
+			env.invalid(EnvBalancing, fmt.Sprintf("is unknown: %q", balancing))
+		}
+	}
+
+	var callOpts []CallOption
+	if timeout, ok := env.duration(EnvTimeout); ok {
+		callOpts = append(callOpts, CallTimeout(timeout))
+	}
+	backoff := DefaultRetryBackoff
+	initial, hasBackoff := env.duration(EnvRetryBackoff)
+	if hasBackoff {
+		backoff.Initial = initial
+	}
+	if _, hasRetries := env.get(EnvRetries); hasBackoff && !hasRetries {
+		env.invalid(EnvRetryBackoff, "goes with "+EnvRetries)
+	}
+	if retries, ok := env.int(EnvRetries); ok {
+		callOpts = append(callOpts, CallRetries(retries, backoff))
+	}
+	if len(callOpts) > 0 {
+		opts = append(opts, WithCallOptions(callOpts...))
+	}
+
+	var settings BreakerSettings
+	var breaker bool
+	settings.Timeout, breaker = env.duration(EnvBreakerTimeout)
+	for _, setting := range []struct {
+		name  string
+		value *int
+	}{
+		{EnvBreakerMaxConcurrentRequests, &settings.MaxConcurrentRequests},
+		{EnvBreakerRequestVolumeThreshold, &settings.RequestVolumeThreshold},
+		{EnvBreakerErrorPercentThreshold, &settings.ErrorPercentThreshold},
+	} {
+		if value, ok := env.int(setting.name); ok {
+			*setting.value, breaker = value, true
+		}
+	}
+	if sleepWindow, ok := env.duration(EnvBreakerSleepWindow); ok {
+		settings.SleepWindow, breaker = sleepWindow, true
+	}
+	if breaker {
+		for _, category := range categories {
+			opts = append(opts, WithBreaker(category, settings))
+		}
+	}
+
+	token, bearer := env.get(EnvBearerToken)
+	username, hasUsername := env.get(EnvBasicAuthUsername)
+	password, hasPassword := env.get(EnvBasicAuthPassword)
+	switch {
+	case bearer && (hasUsername || hasPassword):
+		env.invalid(EnvBearerToken, "cannot be used with the basic authentication")
+	case bearer:
+		opts = append(opts, WithBearerToken(token))
+	case hasUsername != hasPassword:
+		env.invalid(EnvBasicAuthUsername, "goes with "+EnvBasicAuthPassword)
+	case hasUsername:
+		opts = append(opts, WithBasicAuth(username, password))
+	}
+
+	if flavor, ok := env.get(EnvFlavor); ok {
+		f, err := ParseFlavor(flavor)
+		if err != nil {
+			env.invalid(EnvFlavor, fmt.Sprintf("is unknown: %q", flavor))
+		} else {
+			opts = append(opts, WithDefaultFlavor(f))
+		}
+	}
+
+	if caFile, ok := env.get(EnvTLSCAFile); ok {
+		pem, err := os.ReadFile(caFile)
+		pool := x509.NewCertPool()
+		switch {
+		case err != nil:
+			env.invalid(EnvTLSCAFile, err.Error())
+		case !pool.AppendCertsFromPEM(pem):
+			env.invalid(EnvTLSCAFile, "has no PEM certificate: "+caFile)
+		default:
+			opts = append(opts, WithRootCAs(pool))
+		}
+	}
+	certFile, hasCert := env.get(EnvTLSCertFile)
+	keyFile, hasKey := env.get(EnvTLSKeyFile)
+	switch {
+	case hasCert != hasKey:
+		env.invalid(EnvTLSCertFile, "goes with "+EnvTLSKeyFile)
+	case hasCert:
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			env.invalid(EnvTLSCertFile, err.Error())
+			break
+		}
+		opts = append(opts, WithClientCertificate(certificate))
+	}
+
+	if len(env.errors) > 0 {
+		return nil, &EnvError{Variables: env.errors}
+	}
+	return opts, nil
+}
+
+// envReader reads the environment variables, collecting the malformed ones.
+type envReader struct {
+	lookup func(name string) (string, bool)
+	errors []FieldError
+}
+
+func (env *envReader) invalid(name, reason string) {
+	env.errors = append(env.errors, FieldError{Field: name, Reason: reason})
+}
+
+// get returns the value of the variable. Empty variables are ignored.
+func (env *envReader) get(name string) (string, bool) {
+	value, ok := env.lookup(name)
+	value = strings.TrimSpace(value)
+	return value, ok && value != ""
+}
+
+func (env *envReader) urls(name string) []*url.URL {
+	value, ok := env.get(name)
+	if !ok {
+		env.invalid(name, "is required")
+		return nil
+	}
+	var urls []*url.URL
+	for _, s := range strings.Split(value, ",") {
+		u, err := url.Parse(strings.TrimSpace(s))
+		if err != nil || u.Host == "" {
+			env.invalid(name, fmt.Sprintf("is not a URL: %q", s))
+			continue
+		}
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+func (env *envReader) duration(name string) (time.Duration, bool) {
+	value, ok := env.get(name)
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		env.invalid(name, fmt.Sprintf("is not a duration: %q", value))
+		return 0, false
+	}
+	return d, true
+}
+
+func (env *envReader) int(name string) (int, bool) {
+	value, ok := env.get(name)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		env.invalid(name, fmt.Sprintf("is not a positive integer: %q", value))
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,187 @@
+package ketoclient_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/lab259/errors/v2"
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Environment", func() {
+	var keto *fakeKeto
+
+	BeforeEach(func() {
+		keto = newFakeKeto()
+	})
+
+	AfterEach(func() {
+		keto.Close()
+	})
+
+	lookup := func(env map[string]string) func(string) (string, bool) {
+		return func(name string) (string, bool) {
+			value, ok := env[name]
+			return value, ok
+		}
+	}
+
+	newClient := func(env map[string]string) *ketoclient.Client {
+		opts, err := ketoclient.OptionsFromEnv(lookup(env))
+		Expect(err).ToNot(HaveOccurred())
+		return ketoclient.New(append(opts, withUniqueBreakerPrefix())...)
+	}
+
+	It("should only require the URL", func() {
+		client := newClient(map[string]string{
+			ketoclient.EnvURL: keto.URL().String(),
+		})
+		_, err := client.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.DefaultFlavor()).To(Equal(ketoclient.Exact))
+	})
+
+	It("should configure the client", func() {
+		var failures int64 = 1
+		authorization := make(chan string, 10)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization <- r.Header.Get("Authorization")
+			if atomic.AddInt64(&failures, -1) >= 0 {
+				writeJSON(w, http.StatusInternalServerError, &ketoclient.ResponseError{Code: 500, Message: "database is down"})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		}))
+		defer server.Close()
+
+		client := newClient(map[string]string{
+			ketoclient.EnvURL:                          server.URL + ", " + server.URL,
+			ketoclient.EnvBalancing:                    "least-in-flight",
+			ketoclient.EnvTimeout:                      "5s",
+			ketoclient.EnvRetries:                      "1",
+			ketoclient.EnvRetryBackoff:                 "1ms",
+			ketoclient.EnvBreakerTimeout:               "2s",
+			ketoclient.EnvBreakerMaxConcurrentRequests: "50",
+			ketoclient.EnvBreakerSleepWindow:           "10s",
+			ketoclient.EnvBearerToken:                  "s3cr3t",
+			ketoclient.EnvFlavor:                       "Glob",
+		})
+		Expect(client.DefaultFlavor()).To(Equal(ketoclient.Glob))
+		Expect(client.BreakerState(ketoclient.CategoryCheck)).To(Equal(ketoclient.BreakerClosed))
+
+		_, err := client.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(<-authorization).To(Equal("Bearer s3cr3t"))
+		Expect(<-authorization).To(Equal("Bearer s3cr3t"))
+	})
+
+	It("should list all the malformed variables", func() {
+		_, err := ketoclient.OptionsFromEnv(lookup(map[string]string{
+			ketoclient.EnvURL:                          "http://keto:4466,:4466",
+			ketoclient.EnvBalancing:                    "random",
+			ketoclient.EnvTimeout:                      "5",
+			ketoclient.EnvRetries:                      "-1",
+			ketoclient.EnvBreakerErrorPercentThreshold: "half",
+			ketoclient.EnvBearerToken:                  "s3cr3t",
+			ketoclient.EnvBasicAuthPassword:            "passw0rd",
+			ketoclient.EnvFlavor:                       "wildcard",
+			ketoclient.EnvTLSCAFile:                    "/nonexistent/ca.pem",
+			ketoclient.EnvTLSKeyFile:                   "/nonexistent/key.pem",
+		}))
+		Expect(errors.Is(err, ketoclient.ErrInvalidEnv)).To(BeTrue())
+		Expect(err.(*ketoclient.EnvError).Variables).To(Equal([]ketoclient.FieldError{
+			{Field: "KETO_URL", Reason: `is not a URL: ":4466"`},
+			{Field: "KETO_BALANCING", Reason: `is unknown: "random"`},
+			{Field: "KETO_TIMEOUT", Reason: `is not a duration: "5"`},
+			{Field: "KETO_RETRIES", Reason: `is not a positive integer: "-1"`},
+			{Field: "KETO_BREAKER_ERROR_PERCENT_THRESHOLD", Reason: `is not a positive integer: "half"`},
+			{Field: "KETO_BEARER_TOKEN", Reason: "cannot be used with the basic authentication"},
+			{Field: "KETO_FLAVOR", Reason: `is unknown: "wildcard"`},
+			{Field: "KETO_TLS_CA_FILE", Reason: "open /nonexistent/ca.pem: no such file or directory"},
+			{Field: "KETO_TLS_CERT_FILE", Reason: "goes with KETO_TLS_KEY_FILE"},
+		}))
+		Expect(err.Error()).ToNot(ContainSubstring("s3cr3t"))
+		Expect(err.Error()).ToNot(ContainSubstring("passw0rd"))
+	})
+
+	It("should read the retry backoff only with the retries", func() {
+		_, err := ketoclient.OptionsFromEnv(lookup(map[string]string{
+			ketoclient.EnvURL:          "http://keto:4466",
+			ketoclient.EnvRetryBackoff: "100ms",
+		}))
+		Expect(err).To(MatchError("invalid environment: KETO_RETRY_BACKOFF goes with KETO_RETRIES"))
+
+		_, err = ketoclient.OptionsFromEnv(lookup(map[string]string{
+			ketoclient.EnvURL:          "http://keto:4466",
+			ketoclient.EnvRetries:      "-1",
+			ketoclient.EnvRetryBackoff: "soon",
+		}))
+		Expect(err.(*ketoclient.EnvError).Variables).To(Equal([]ketoclient.FieldError{
+			{Field: "KETO_RETRY_BACKOFF", Reason: `is not a duration: "soon"`},
+			{Field: "KETO_RETRIES", Reason: `is not a positive integer: "-1"`},
+		}))
+	})
+
+	It("should require the URL", func() {
+		_, err := ketoclient.OptionsFromEnv(lookup(map[string]string{
+			ketoclient.EnvURL: " ",
+		}))
+		Expect(err).To(MatchError("invalid environment: KETO_URL is required"))
+	})
+
+	It("should read the TLS files", func() {
+		certificate, leaf := newClientCertificate()
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(leaf)
+
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]string{"status": r.TLS.PeerCertificates[0].Subject.CommonName})
+		}))
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+		server.StartTLS()
+		defer server.Close()
+
+		dir, err := os.MkdirTemp("", "ketoclient")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		writePEM := func(name, kind string, der []byte) string {
+			path := filepath.Join(dir, name)
+			Expect(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0600)).To(Succeed())
+			return path
+		}
+		key, err := x509.MarshalPKCS8PrivateKey(certificate.PrivateKey)
+		Expect(err).ToNot(HaveOccurred())
+
+		client := newClient(map[string]string{
+			ketoclient.EnvURL:         server.URL,
+			ketoclient.EnvTLSCAFile:   writePEM("ca.pem", "CERTIFICATE", server.Certificate().Raw),
+			ketoclient.EnvTLSCertFile: writePEM("cert.pem", "CERTIFICATE", leaf.Raw),
+			ketoclient.EnvTLSKeyFile:  writePEM("key.pem", "PRIVATE KEY", key),
+		})
+		response, err := client.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status).To(Equal("ketoclient"))
+	})
+
+	It("should create a client from the process environment", func() {
+		defer os.Unsetenv(ketoclient.EnvURL)
+		Expect(os.Setenv(ketoclient.EnvURL, keto.URL().String())).To(Succeed())
+
+		client, err := ketoclient.NewFromEnv(withUniqueBreakerPrefix())
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Version()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(os.Setenv(ketoclient.EnvURL, "")).To(Succeed())
+		_, err = ketoclient.NewFromEnv()
+		Expect(errors.Is(err, ketoclient.ErrInvalidEnv)).To(BeTrue())
+	})
+})
@@ -0,0 +1,178 @@
+package ketoclient
+
+// SkipReason describes why a policy did not match a request.
+type SkipReason string
+
+const (
+	// SubjectMismatch means that neither the subject nor any of its roles are
+	// in the policy subjects.
+	SubjectMismatch SkipReason = "subject"
+
+	// ActionMismatch means that the action is not in the policy actions.
+	ActionMismatch SkipReason = "action"
+
+	// ResourceMismatch means that the resource is not in the policy
+	// resources.
+	ResourceMismatch SkipReason = "resource"
+
+	// ConditionMismatch means that at least one of the policy conditions is
+	// not fulfilled by the request context.
+	ConditionMismatch SkipReason = "condition"
+)
+
+// PolicyEvaluation is the result of checking a request against a single
+// policy.
+type PolicyEvaluation struct {
+	Policy ORYAccessControlPolicy `json:"policy"`
+	Reason SkipReason             `json:"reason,omitempty"`
+	Detail string                 `json:"detail,omitempty"`
+}
+
+// Explanation describes how a decision was reached.
+type Explanation struct {
+	Request *AllowedORYAccessControlPolicyRequest `json:"request"`
+	Flavor  Flavor                                `json:"flavor"`
+	Roles   []string                              `json:"roles"`
+	Allowed bool                                  `json:"allowed"`
+	Matched []PolicyEvaluation                    `json:"matched"`
+	Skipped []PolicyEvaluation                    `json:"skipped"`
+
+	// DecidedBy is the policy that determined the final outcome. It is nil
+	// when no policy matched and the request was denied by default.
+	DecidedBy *ORYAccessControlPolicy `json:"decided_by"`
+}
+
+// Evaluator decides requests locally using the same rules as the Keto ORY
+// Access Control Policy engine: any matching deny policy overrides the allow
+// policies and, when no policy matches, the request is denied.
+type Evaluator struct {
+	flavor   Flavor
+	policies []ORYAccessControlPolicy
+	roles    []ORYAccessControlRole
+}
+
+// NewEvaluator creates an `Evaluator` for the given set of policies and roles.
+func NewEvaluator(flavor Flavor, policies []ORYAccessControlPolicy, roles []ORYAccessControlRole) *Evaluator {
+	return &Evaluator{
+		flavor:   flavor,
+		policies: policies,
+		roles:    roles,
+	}
+}
+
+// Flavor returns the flavor the evaluator decides with.
+func (e *Evaluator) Flavor() Flavor {
+	return e.flavor
+}
+
+// Allowed checks if a request is allowed.
+func (e *Evaluator) Allowed(request *AllowedORYAccessControlPolicyRequest) (bool, error) {
+	explanation, err := e.Explain(request)
+	if err != nil {
+		return false, err
+	}
+	return explanation.Allowed, nil
+}
+
+// Explain checks a request reporting which policies matched it, which were
+// skipped and why, and which one determined the final outcome.
+func (e *Evaluator) Explain(request *AllowedORYAccessControlPolicyRequest) (*Explanation, error) {
+	r := &Explanation{
+		Request: request,
+		Flavor:  e.flavor,
+		Roles:   subjectRoles(request.Subject, e.roles),
+		Matched: make([]PolicyEvaluation, 0),
+		Skipped: make([]PolicyEvaluation, 0),
+	}
+
+	subjects := append([]string{request.Subject}, r.Roles...)
+	for _, policy := range e.policies {
+		reason, detail, err := e.evaluate(policy, subjects, request)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			r.Skipped = append(r.Skipped, PolicyEvaluation{
+				Policy: policy,
+				Reason: reason,
+				Detail: detail,
+			})
+			continue
+		}
+		r.Matched = append(r.Matched, PolicyEvaluation{Policy: policy})
+	}
+
+	for i := range r.Matched {
+		if r.Matched[i].Policy.Effect == Deny {
+			r.DecidedBy = &r.Matched[i].Policy
+			return r, nil
+		}
+	}
+	for i := range r.Matched {
+		if r.Matched[i].Policy.Effect == Allow {
+			r.DecidedBy = &r.Matched[i].Policy
+			r.Allowed = true
+			return r, nil
+		}
+	}
+	return r, nil
+}
+
+func (e *Evaluator) evaluate(policy ORYAccessControlPolicy, subjects []string, request *AllowedORYAccessControlPolicyRequest) (SkipReason, string, error) {
+	subjectMatched := false
+	for _, subject := range subjects {
+		ok, err := matchAny(e.flavor, policy.Subjects, subject)
+		if err != nil {
+			return "", "", err
+		}
+		if ok {
+			subjectMatched = true
+			break
+		}
+	}
+	if !subjectMatched {
+		return SubjectMismatch, "", nil
+	}
+
+	ok, err := matchAny(e.flavor, policy.Actions, request.Action)
+	if err != nil {
+		return "", "", err
+	}
+	if !ok {
+		return ActionMismatch, "", nil
+	}
+
+	ok, err = matchAny(e.flavor, policy.Resources, request.Resource)
+	if err != nil {
+		return "", "", err
+	}
+	if !ok {
+		return ResourceMismatch, "", nil
+	}
+
+	ok, detail, err := evaluateConditions(policy.Conditions, request)
+	if err != nil {
+		return "", "", err
+	}
+	if !ok {
+		return ConditionMismatch, detail, nil
+	}
+	return "", "", nil
+}
+
+// ExplainOryAccessControlPolicy fetches the policies and roles of the flavor
+// and explains the decision for the request.
+//
+// The decision is computed locally by an `Evaluator`, hence it does not
+// contact the allowed endpoint.
+func (client *Client) ExplainOryAccessControlPolicy(flavor Flavor, request *AllowedORYAccessControlPolicyRequest) (*Explanation, error) {
+	policies, err := client.listAllOryAccessControlPolicies(flavor)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := client.listAllOryAccessControlRoles(flavor)
+	if err != nil {
+		return nil, err
+	}
+	return NewEvaluator(flavor, policies, roles).Explain(request)
+}
@@ -0,0 +1,133 @@
+package ketoclient_test
+
+import (
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExplainOryAccessControlPolicy", func() {
+	var keto *fakeKeto
+
+	BeforeEach(func() {
+		keto = newFakeKeto()
+		keto.AddRoles(ketoclient.Exact, ketoclient.ORYAccessControlRole{
+			ID:      "role:editors",
+			Members: []string{"user:snake-eyes"},
+		})
+		keto.AddPolicies(ketoclient.Exact, ketoclient.ORYAccessControlPolicy{
+			ID:        "allow-editors",
+			Subjects:  []string{"role:editors"},
+			Resources: []string{"blog1:post:33", "blog1:post:34"},
+			Actions:   []string{"edit"},
+			Effect:    ketoclient.Allow,
+		}, ketoclient.ORYAccessControlPolicy{
+			ID:        "allow-admins",
+			Subjects:  []string{"role:admins"},
+			Resources: []string{"blog1:post:33"},
+			Actions:   []string{"edit"},
+			Effect:    ketoclient.Allow,
+		}, ketoclient.ORYAccessControlPolicy{
+			ID:        "allow-delete",
+			Subjects:  []string{"user:snake-eyes"},
+			Resources: []string{"blog1:post:33"},
+			Actions:   []string{"delete"},
+			Effect:    ketoclient.Allow,
+		}, ketoclient.ORYAccessControlPolicy{
+			ID:        "deny-post-34",
+			Subjects:  []string{"user:snake-eyes"},
+			Resources: []string{"blog1:post:34"},
+			Actions:   []string{"edit"},
+			Effect:    ketoclient.Deny,
+		}, ketoclient.ORYAccessControlPolicy{
+			ID:        "deny-outside-office",
+			Subjects:  []string{"role:editors"},
+			Resources: []string{"blog1:post:33"},
+			Actions:   []string{"edit"},
+			Effect:    ketoclient.Deny,
+			Conditions: map[string]interface{}{
+				"remoteIP": map[string]interface{}{
+					"type":    "CIDRCondition",
+					"options": map[string]interface{}{"cidr": "10.0.0.0/8"},
+				},
+			},
+		})
+	})
+
+	AfterEach(func() {
+		keto.Close()
+	})
+
+	It("should explain an allowed request", func() {
+		explanation, err := keto.Client().ExplainOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "edit",
+			Resource: "blog1:post:33",
+			Context:  map[string]interface{}{"remoteIP": "192.168.0.1"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(explanation.Allowed).To(BeTrue())
+		Expect(explanation.Roles).To(ConsistOf("role:editors"))
+		Expect(explanation.DecidedBy).ToNot(BeNil())
+		Expect(explanation.DecidedBy.ID).To(Equal("allow-editors"))
+		Expect(explanation.Matched).To(HaveLen(1))
+
+		reasons := make(map[string]ketoclient.SkipReason)
+		details := make(map[string]string)
+		for _, skipped := range explanation.Skipped {
+			reasons[skipped.Policy.ID] = skipped.Reason
+			details[skipped.Policy.ID] = skipped.Detail
+		}
+		Expect(reasons).To(Equal(map[string]ketoclient.SkipReason{
+			"allow-admins":        ketoclient.SubjectMismatch,
+			"allow-delete":        ketoclient.ActionMismatch,
+			"deny-post-34":        ketoclient.ResourceMismatch,
+			"deny-outside-office": ketoclient.ConditionMismatch,
+		}))
+		Expect(details["deny-outside-office"]).To(ContainSubstring("remoteIP"))
+	})
+
+	It("should explain a request denied by a deny policy", func() {
+		explanation, err := keto.Client().ExplainOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "edit",
+			Resource: "blog1:post:34",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(explanation.Allowed).To(BeFalse())
+		Expect(explanation.Matched).To(HaveLen(2))
+		Expect(explanation.DecidedBy).ToNot(BeNil())
+		Expect(explanation.DecidedBy.ID).To(Equal("deny-post-34"))
+	})
+
+	It("should explain a request denied by default", func() {
+		explanation, err := keto.Client().ExplainOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:scarlet",
+			Action:   "edit",
+			Resource: "blog1:post:33",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(explanation.Allowed).To(BeFalse())
+		Expect(explanation.Matched).To(BeEmpty())
+		Expect(explanation.Skipped).To(HaveLen(5))
+		Expect(explanation.DecidedBy).To(BeNil())
+	})
+
+	It("should agree with the server decision", func() {
+		client := keto.Client()
+		request := &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "edit",
+			Resource: "blog1:post:33",
+			Context:  map[string]interface{}{"remoteIP": "10.0.0.1"},
+		}
+
+		explanation, err := client.ExplainOryAccessControlPolicy(ketoclient.Exact, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(explanation.DecidedBy.ID).To(Equal("deny-outside-office"))
+
+		response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Allowed).To(Equal(explanation.Allowed))
+	})
+})
@@ -0,0 +1,280 @@
+package ketoclient_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+)
+
+// fakeKeto is an in memory implementation of the Keto ACP endpoints used by
+// the tests that cannot rely on a running Keto instance.
+type fakeKeto struct {
+	mu       sync.Mutex
+	policies map[ketoclient.Flavor][]ketoclient.ORYAccessControlPolicy
+	roles    map[ketoclient.Flavor][]ketoclient.ORYAccessControlRole
+	version  string
+	requests int
+	server   *httptest.Server
+
+	// allowed decides the checks. When nil, the stored policies are evaluated
+	// by a `ketoclient.Evaluator`.
+	allowed func(flavor ketoclient.Flavor, request *ketoclient.AllowedORYAccessControlPolicyRequest) bool
+}
+
+func newFakeKeto() *fakeKeto {
+	f := &fakeKeto{
+		policies: make(map[ketoclient.Flavor][]ketoclient.ORYAccessControlPolicy),
+		roles:    make(map[ketoclient.Flavor][]ketoclient.ORYAccessControlRole),
+		version:  "v0.3.3-sandbox",
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+func (f *fakeKeto) URL() *url.URL {
+	u, err := url.Parse(f.server.URL)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func (f *fakeKeto) Client(opts ...ketoclient.Option) *ketoclient.Client {
+	return ketoclient.New(append([]ketoclient.Option{
+		ketoclient.WithURL(f.URL()),
+		withUniqueBreakerPrefix(),
+	}, opts...)...)
+}
+
+var breakerPrefixes int64
+
+// withUniqueBreakerPrefix isolates the circuit breakers of a test client, as
+// hystrix keeps them globally.
+func withUniqueBreakerPrefix() ketoclient.Option {
+	return ketoclient.WithBreakerPrefix(fmt.Sprintf("ketoclient-test-%d", atomic.AddInt64(&breakerPrefixes, 1)))
+}
+
+func (f *fakeKeto) Close() {
+	f.server.Close()
+}
+
+func (f *fakeKeto) Requests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func (f *fakeKeto) AddPolicies(flavor ketoclient.Flavor, policies ...ketoclient.ORYAccessControlPolicy) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, policy := range policies {
+		f.upsertPolicy(flavor, policy)
+	}
+}
+
+func (f *fakeKeto) AddRoles(flavor ketoclient.Flavor, roles ...ketoclient.ORYAccessControlRole) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, role := range roles {
+		f.upsertRole(flavor, role)
+	}
+}
+
+func (f *fakeKeto) upsertPolicy(flavor ketoclient.Flavor, policy ketoclient.ORYAccessControlPolicy) {
+	for i, p := range f.policies[flavor] {
+		if p.ID == policy.ID {
+			f.policies[flavor][i] = policy
+			return
+		}
+	}
+	f.policies[flavor] = append(f.policies[flavor], policy)
+}
+
+func (f *fakeKeto) upsertRole(flavor ketoclient.Flavor, role ketoclient.ORYAccessControlRole) {
+	for i, r := range f.roles[flavor] {
+		if r.ID == role.ID {
+			f.roles[flavor][i] = role
+			return
+		}
+	}
+	f.roles[flavor] = append(f.roles[flavor], role)
+}
+
+func (f *fakeKeto) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/health/alive", r.URL.Path == "/health/ready":
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	case r.URL.Path == "/version":
+		writeJSON(w, http.StatusOK, map[string]string{"version": f.version})
+	case len(parts) >= 5 && parts[0] == "engines" && parts[1] == "acp" && parts[2] == "ory":
+		flavor := ketoclient.Flavor(parts[3])
+		switch parts[4] {
+		case "allowed":
+			f.serveAllowed(w, r, flavor)
+		case "policies":
+			f.servePolicies(w, r, flavor, parts[5:])
+		case "roles":
+			f.serveRoles(w, r, flavor, parts[5:])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeKeto) serveAllowed(w http.ResponseWriter, r *http.Request, flavor ketoclient.Flavor) {
+	request := &ketoclient.AllowedORYAccessControlPolicyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		writeJSON(w, http.StatusInternalServerError, &ketoclient.ResponseError{Code: 500, Message: err.Error()})
+		return
+	}
+	allowed := f.allowed
+	if allowed == nil {
+		allowed = func(flavor ketoclient.Flavor, request *ketoclient.AllowedORYAccessControlPolicyRequest) bool {
+			ok, _ := ketoclient.NewEvaluator(flavor, f.policies[flavor], f.roles[flavor]).Allowed(request)
+			return ok
+		}
+	}
+	if allowed(flavor, request) {
+		writeJSON(w, http.StatusOK, &ketoclient.AllowedORYAccessControlPolicyResponse{Allowed: true})
+		return
+	}
+	writeJSON(w, http.StatusForbidden, &ketoclient.AllowedORYAccessControlPolicyResponse{Allowed: false})
+}
+
+func (f *fakeKeto) servePolicies(w http.ResponseWriter, r *http.Request, flavor ketoclient.Flavor, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodPut:
+		policy := ketoclient.ORYAccessControlPolicy{}
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			writeJSON(w, http.StatusInternalServerError, &ketoclient.ResponseError{Code: 500, Message: err.Error()})
+			return
+		}
+		f.upsertPolicy(flavor, policy)
+		writeJSON(w, http.StatusOK, policy)
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		policies := f.policies[flavor]
+		from, to := page(r, len(policies))
+		writeJSON(w, http.StatusOK, append([]ketoclient.ORYAccessControlPolicy{}, policies[from:to]...))
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		for _, policy := range f.policies[flavor] {
+			if policy.ID == parts[0] {
+				writeJSON(w, http.StatusOK, policy)
+				return
+			}
+		}
+		writeJSON(w, http.StatusNotFound, &ketoclient.ResponseError{Code: 404, Message: "not found"})
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		policies := f.policies[flavor][:0]
+		for _, policy := range f.policies[flavor] {
+			if policy.ID != parts[0] {
+				policies = append(policies, policy)
+			}
+		}
+		f.policies[flavor] = policies
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeKeto) serveRoles(w http.ResponseWriter, r *http.Request, flavor ketoclient.Flavor, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodPut:
+		role := ketoclient.ORYAccessControlRole{}
+		if err := json.NewDecoder(r.Body).Decode(&role); err != nil {
+			writeJSON(w, http.StatusInternalServerError, &ketoclient.ResponseError{Code: 500, Message: err.Error()})
+			return
+		}
+		f.upsertRole(flavor, role)
+		writeJSON(w, http.StatusOK, role)
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		roles := f.roles[flavor]
+		from, to := page(r, len(roles))
+		writeJSON(w, http.StatusOK, append([]ketoclient.ORYAccessControlRole{}, roles[from:to]...))
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		for _, role := range f.roles[flavor] {
+			if role.ID == parts[0] {
+				writeJSON(w, http.StatusOK, role)
+				return
+			}
+		}
+		writeJSON(w, http.StatusNotFound, &ketoclient.ResponseError{Code: 404, Message: "not found"})
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		roles := f.roles[flavor][:0]
+		for _, role := range f.roles[flavor] {
+			if role.ID != parts[0] {
+				roles = append(roles, role)
+			}
+		}
+		f.roles[flavor] = roles
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 2 && parts[1] == "members" && r.Method == http.MethodPut:
+		request := ketoclient.AddMembersORYAccessRoleRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeJSON(w, http.StatusInternalServerError, &ketoclient.ResponseError{Code: 500, Message: err.Error()})
+			return
+		}
+		role := ketoclient.ORYAccessControlRole{ID: parts[0]}
+		for _, existing := range f.roles[flavor] {
+			if existing.ID == parts[0] {
+				role = existing
+			}
+		}
+		role.Members = append(append([]string{}, role.Members...), request.Members...)
+		f.upsertRole(flavor, role)
+		writeJSON(w, http.StatusOK, role)
+	case len(parts) == 3 && parts[1] == "members" && r.Method == http.MethodDelete:
+		for i, role := range f.roles[flavor] {
+			if role.ID != parts[0] {
+				continue
+			}
+			members := make([]string, 0, len(role.Members))
+			for _, member := range role.Members {
+				if member != parts[2] {
+					members = append(members, member)
+				}
+			}
+			f.roles[flavor][i].Members = members
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func page(r *http.Request, total int) (int, int) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if limit <= 0 {
+		limit = 100
+	}
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return offset, end
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
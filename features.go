@@ -0,0 +1,173 @@
+package ketoclient
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/blang/semver"
+	"github.com/lab259/errors/v2"
+)
+
+// ErrUnsupported is the reason of all `*UnsupportedError`.
+var ErrUnsupported = errors.New("unsupported by server")
+
+// Feature is a functionality that may or may not be provided by the server,
+// depending on its version.
+type Feature string
+
+const (
+	// FeatureACP are the ORY Access Control Policy engines, removed in
+	// v0.6.0.
+	FeatureACP Feature = "acp engines"
+
+	// FeatureRelationTuples is the relation tuples API, added in v0.6.0.
+	FeatureRelationTuples Feature = "relation tuples"
+
+	// FeatureReadWriteAPIs means that the server exposes separate read and
+	// write APIs, added in v0.6.0.
+	FeatureReadWriteAPIs Feature = "separate read/write apis"
+
+	// FeatureGRPC is the gRPC API, added in v0.6.0.
+	FeatureGRPC Feature = "grpc"
+)
+
+// PaginationStyle is how the server paginates its listings.
+type PaginationStyle string
+
+const (
+	// PaginationOffset uses the `limit` and `offset` query parameters.
+	PaginationOffset PaginationStyle = "offset"
+
+	// PaginationToken uses the `page_size` and `page_token` query parameters.
+	PaginationToken PaginationStyle = "token"
+)
+
+// Capabilities describes what the server supports.
+type Capabilities struct {
+	Version        string          `json:"version"`
+	ACPEngines     []Flavor        `json:"acp_engines"`
+	RelationTuples bool            `json:"relation_tuples"`
+	ReadWriteAPIs  bool            `json:"read_write_apis"`
+	GRPC           bool            `json:"grpc"`
+	Pagination     PaginationStyle `json:"pagination"`
+}
+
+// relationTuplesVersion is the first version with relation tuples and
+// without ACP engines. Pre-releases are considered part of the release.
+var relationTuplesVersion = semver.Version{Major: 0, Minor: 6}
+
+// newCapabilities builds the capabilities of a server version.
+func newCapabilities(version string) (*Capabilities, error) {
+	v, err := parseServerVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	v.Pre = nil
+	v.Build = nil
+
+	if v.LT(relationTuplesVersion) {
+		return &Capabilities{
+			Version:    version,
+			ACPEngines: []Flavor{Exact, Glob, Regex},
+			Pagination: PaginationOffset,
+		}, nil
+	}
+	return &Capabilities{
+		Version:        version,
+		ACPEngines:     []Flavor{},
+		RelationTuples: true,
+		ReadWriteAPIs:  true,
+		GRPC:           true,
+		Pagination:     PaginationToken,
+	}, nil
+}
+
+// Supports reports if the server provides the feature.
+func (c *Capabilities) Supports(feature Feature) bool {
+	switch feature {
+	case FeatureACP:
+		return len(c.ACPEngines) > 0
+	case FeatureRelationTuples:
+		return c.RelationTuples
+	case FeatureReadWriteAPIs:
+		return c.ReadWriteAPIs
+	case FeatureGRPC:
+		return c.GRPC
+	}
+	return false
+}
+
+// UnsupportedError is returned when a method requires a feature that the
+// server does not provide.
+type UnsupportedError struct {
+	Feature Feature
+	Version string
+}
+
+func (err *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s unsupported by server version %s", err.Feature, err.Version)
+}
+
+// Unwrap returns `ErrUnsupported`.
+func (err *UnsupportedError) Unwrap() error {
+	return ErrUnsupported
+}
+
+// featureDetector probes the server once and keeps its capabilities.
+type featureDetector struct {
+	// auto makes the client probe the server before its first request.
+	auto bool
+
+	mu           sync.Mutex
+	capabilities *Capabilities
+}
+
+func (d *featureDetector) get() *Capabilities {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.capabilities
+}
+
+// Capabilities returns what the server supports. The server version is
+// probed on the first call only, unless it fails.
+//
+// Once the capabilities are known, methods that the server does not support
+// fail with an `*UnsupportedError`.
+func (client *Client) Capabilities() (*Capabilities, error) {
+	if c := client.features.get(); c != nil {
+		return c, nil
+	}
+
+	response, err := client.Version()
+	if err != nil {
+		return nil, err
+	}
+	c, err := newCapabilities(response.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	client.features.mu.Lock()
+	defer client.features.mu.Unlock()
+	client.features.capabilities = c
+	return c, nil
+}
+
+// require fails when the server is known not to support the feature.
+func (client *Client) require(feature Feature) error {
+	c := client.features.get()
+	if c == nil {
+		if !client.features.auto {
+			return nil
+		}
+		var err error
+		c, err = client.Capabilities()
+		if err != nil {
+			return err
+		}
+	}
+	if !c.Supports(feature) {
+		return &UnsupportedError{Feature: feature, Version: c.Version}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package ketoclient_test
+
+import (
+	"context"
+
+	"github.com/lab259/errors/v2"
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Capabilities", func() {
+	var keto *fakeKeto
+
+	BeforeEach(func() {
+		keto = newFakeKeto()
+	})
+
+	AfterEach(func() {
+		keto.Close()
+	})
+
+	It("should detect the capabilities of a server with ACP engines", func() {
+		client := keto.Client()
+		capabilities, err := client.Capabilities()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(capabilities).To(Equal(&ketoclient.Capabilities{
+			Version:    "v0.3.3-sandbox",
+			ACPEngines: []ketoclient.Flavor{ketoclient.Exact, ketoclient.Glob, ketoclient.Regex},
+			Pagination: ketoclient.PaginationOffset,
+		}))
+		Expect(capabilities.Supports(ketoclient.FeatureACP)).To(BeTrue())
+		Expect(capabilities.Supports(ketoclient.FeatureRelationTuples)).To(BeFalse())
+	})
+
+	It("should detect the capabilities of a server with relation tuples", func() {
+		keto.version = "v0.6.0-alpha.1"
+		capabilities, err := keto.Client().Capabilities()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(capabilities.Supports(ketoclient.FeatureACP)).To(BeFalse())
+		Expect(capabilities.Supports(ketoclient.FeatureRelationTuples)).To(BeTrue())
+		Expect(capabilities.Supports(ketoclient.FeatureReadWriteAPIs)).To(BeTrue())
+		Expect(capabilities.Supports(ketoclient.FeatureGRPC)).To(BeTrue())
+		Expect(capabilities.Pagination).To(Equal(ketoclient.PaginationToken))
+	})
+
+	It("should probe the server only once", func() {
+		client := keto.Client()
+		_, err := client.Capabilities()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.WithContext(context.Background()).Capabilities()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keto.Requests()).To(Equal(1))
+	})
+
+	It("should fail the methods the server does not support", func() {
+		keto.version = "v0.6.0"
+		client := keto.Client(ketoclient.WithFeatureDetection())
+
+		_, err := client.GetOryAccessControlPolicy(ketoclient.Exact, "id1")
+		Expect(errors.Is(err, ketoclient.ErrUnsupported)).To(BeTrue())
+		Expect(err.Error()).To(Equal("acp engines unsupported by server version v0.6.0"))
+
+		_, err = client.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keto.Requests()).To(Equal(2))
+	})
+
+	It("should not probe the server unless asked to", func() {
+		keto.version = "v0.6.0"
+		_, err := keto.Client().ListOryAccessControlPolicy(ketoclient.Exact, &ketoclient.ListORYAccessPolicyRequest{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keto.Requests()).To(Equal(1))
+	})
+})
@@ -0,0 +1,155 @@
+package ketoclient
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/lab259/errors/v2"
+)
+
+// ErrUnknownFlavor is returned when parsing a value that is not a flavor.
+var ErrUnknownFlavor = errors.New("unknown flavor, expected exact, glob or regex")
+
+// ParseFlavor parses the name of a flavor, ignoring the case and the
+// surrounding spaces.
+func ParseFlavor(s string) (Flavor, error) {
+	flavor := Flavor(strings.ToLower(strings.TrimSpace(s)))
+	if !flavor.known() {
+		return "", errors.Wrap(ErrUnknownFlavor, errors.Message(strconv.Quote(s)))
+	}
+	return flavor, nil
+}
+
+func (f Flavor) known() bool {
+	switch f {
+	case Exact, Glob, Regex:
+		return true
+	}
+	return false
+}
+
+func (f Flavor) String() string {
+	return string(f)
+}
+
+// MarshalText implements `encoding.TextMarshaler`.
+func (f Flavor) MarshalText() ([]byte, error) {
+	return []byte(f), nil
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`, so flavors can be
+// read from JSON, YAML or environment variables. It fails with
+// `ErrUnknownFlavor` as `ParseFlavor` does.
+func (f *Flavor) UnmarshalText(text []byte) error {
+	flavor, err := ParseFlavor(string(text))
+	if err != nil {
+		return err
+	}
+	*f = flavor
+	return nil
+}
+
+// Set implements `flag.Value`, so flavors can be given as flags:
+//
+//	flavor := ketoclient.Exact
+//	flag.Var(&flavor, "flavor", "exact, glob or regex")
+func (f *Flavor) Set(s string) error {
+	return f.UnmarshalText([]byte(s))
+}
+
+// FlavorClient is a view of a `Client` bound to a flavor, whose methods do
+// not take the flavor.
+type FlavorClient struct {
+	client *Client
+	flavor Flavor
+}
+
+// Flavor creates a view of the client bound to the flavor. An unknown flavor
+// fails the calls of the view with a `*ValidationError`.
+func (client *Client) Flavor(flavor Flavor) *FlavorClient {
+	return &FlavorClient{
+		client: client,
+		flavor: flavor,
+	}
+}
+
+// DefaultFlavor returns the flavor defined by `WithDefaultFlavor`, or `Exact`.
+func (client *Client) DefaultFlavor() Flavor {
+	if client.defaultFlavor == "" {
+		return Exact
+	}
+	return client.defaultFlavor
+}
+
+// Flavor returns the flavor of the view.
+func (f *FlavorClient) Flavor() Flavor {
+	return f.flavor
+}
+
+// Client returns the client of the view.
+func (f *FlavorClient) Client() *Client {
+	return f.client
+}
+
+// WithContext returns a copy of the view whose requests are bound to the
+// given context. See `Client.WithContext`.
+func (f *FlavorClient) WithContext(ctx context.Context) *FlavorClient {
+	return f.client.WithContext(ctx).Flavor(f.flavor)
+}
+
+// AllowedOryAccessControlPolicy check if a request is allowed.
+func (f *FlavorClient) AllowedOryAccessControlPolicy(request *AllowedORYAccessControlPolicyRequest, opts ...CallOption) (*AllowedORYAccessControlPolicyResponse, error) {
+	return f.client.AllowedOryAccessControlPolicy(f.flavor, request, opts...)
+}
+
+// UpsertOryAccessControlPolicy an ORY Access Control Policy.
+func (f *FlavorClient) UpsertOryAccessControlPolicy(request *UpsertORYAccessPolicyRequest, opts ...CallOption) (*UpsertORYAccessPolicyResponseOK, error) {
+	return f.client.UpsertOryAccessControlPolicy(f.flavor, request, opts...)
+}
+
+// ListOryAccessControlPolicy list ORY Access Control Policies.
+func (f *FlavorClient) ListOryAccessControlPolicy(request *ListORYAccessPolicyRequest, opts ...CallOption) (*ListORYAccessPolicyResponseOK, error) {
+	return f.client.ListOryAccessControlPolicy(f.flavor, request, opts...)
+}
+
+// GetOryAccessControlPolicy returns an ORY Access Control Policy by ID.
+func (f *FlavorClient) GetOryAccessControlPolicy(id string, opts ...CallOption) (*GetORYAccessPolicyResponseOK, error) {
+	return f.client.GetOryAccessControlPolicy(f.flavor, id, opts...)
+}
+
+// DeleteOryAccessControlPolicy deletes an ORY Access Control Policy.
+func (f *FlavorClient) DeleteOryAccessControlPolicy(id string, opts ...CallOption) error {
+	return f.client.DeleteOryAccessControlPolicy(f.flavor, id, opts...)
+}
+
+// UpsertOryAccessControlRole update or insert a ORY Access Control Role.
+func (f *FlavorClient) UpsertOryAccessControlRole(request *UpsertORYAccessRoleRequest, opts ...CallOption) (*UpsertORYAccessRoleResponseOK, error) {
+	return f.client.UpsertOryAccessControlRole(f.flavor, request, opts...)
+}
+
+// GetOryAccessControlRole return a ORY Access Control Role by ID.
+func (f *FlavorClient) GetOryAccessControlRole(id string, opts ...CallOption) (*GetORYAccessRoleResponseOK, error) {
+	return f.client.GetOryAccessControlRole(f.flavor, id, opts...)
+}
+
+// ListOryAccessControlRole list ORY Access Control Roles.
+func (f *FlavorClient) ListOryAccessControlRole(request *ListORYAccessRoleRequest, opts ...CallOption) (*ListORYAccessRoleResponseOK, error) {
+	return f.client.ListOryAccessControlRole(f.flavor, request, opts...)
+}
+
+// DeleteOryAccessControlRole deletes an ORY Access Control Role.
+func (f *FlavorClient) DeleteOryAccessControlRole(id string, opts ...CallOption) error {
+	return f.client.DeleteOryAccessControlRole(f.flavor, id, opts...)
+}
+
+// AddMembersOryAccessControlRole adds members to an ORY Access Control Role.
+func (f *FlavorClient) AddMembersOryAccessControlRole(id string, request *AddMembersORYAccessRoleRequest, opts ...CallOption) (*AddMembersORYAccessRoleResponseOK, error) {
+	return f.client.AddMembersOryAccessControlRole(f.flavor, id, request, opts...)
+}
+
+// RemoveMemberOryAccessControlRole removes a member from an ORY Access Control
+// Role.
+func (f *FlavorClient) RemoveMemberOryAccessControlRole(id, member string, opts ...CallOption) error {
+	return f.client.RemoveMemberOryAccessControlRole(f.flavor, id, member, opts...)
+}
@@ -0,0 +1,132 @@
+package ketoclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+
+	"github.com/lab259/errors/v2"
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Flavor", func() {
+	table.DescribeTable("should parse the flavors",
+		func(s string, expected ketoclient.Flavor) {
+			flavor, err := ketoclient.ParseFlavor(s)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(flavor).To(Equal(expected))
+		},
+		table.Entry("exact", "exact", ketoclient.Exact),
+		table.Entry("glob", "glob", ketoclient.Glob),
+		table.Entry("regex", "regex", ketoclient.Regex),
+		table.Entry("upper case", "GLOB", ketoclient.Glob),
+		table.Entry("surrounding spaces", " regex\n", ketoclient.Regex),
+	)
+
+	table.DescribeTable("should fail parsing the unknown flavors",
+		func(s string) {
+			_, err := ketoclient.ParseFlavor(s)
+			Expect(errors.Is(err, ketoclient.ErrUnknownFlavor)).To(BeTrue())
+		},
+		table.Entry("empty", ""),
+		table.Entry("unknown", "wildcard"),
+		table.Entry("prefix", "glo"),
+	)
+
+	It("should be encoded as text", func() {
+		var config struct {
+			Flavor  ketoclient.Flavor   `json:"flavor"`
+			Flavors []ketoclient.Flavor `json:"flavors"`
+		}
+		Expect(json.Unmarshal([]byte(`{"flavor":"Glob","flavors":["exact","regex"]}`), &config)).To(Succeed())
+		Expect(config.Flavor).To(Equal(ketoclient.Glob))
+		Expect(config.Flavors).To(Equal([]ketoclient.Flavor{ketoclient.Exact, ketoclient.Regex}))
+
+		data, err := json.Marshal(config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"flavor":"glob","flavors":["exact","regex"]}`))
+
+		err = json.Unmarshal([]byte(`{"flavor":"wildcard"}`), &config)
+		Expect(errors.Is(err, ketoclient.ErrUnknownFlavor)).To(BeTrue())
+	})
+
+	It("should be given as a flag", func() {
+		flavor := ketoclient.Exact
+		flags := flag.NewFlagSet("keto", flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		flags.Var(&flavor, "flavor", "exact, glob or regex")
+
+		Expect(flags.Parse([]string{"-flavor", "glob"})).To(Succeed())
+		Expect(flavor).To(Equal(ketoclient.Glob))
+		Expect(flags.Lookup("flavor").DefValue).To(Equal("exact"))
+
+		Expect(flags.Parse([]string{"-flavor", "wildcard"})).ToNot(Succeed())
+		Expect(flavor).To(Equal(ketoclient.Glob))
+	})
+
+	Describe("FlavorClient", func() {
+		var keto *fakeKeto
+
+		BeforeEach(func() {
+			keto = newFakeKeto()
+		})
+
+		AfterEach(func() {
+			keto.Close()
+		})
+
+		It("should bind the calls to the flavor", func() {
+			client := keto.Client().Flavor(ketoclient.Glob)
+			Expect(client.Flavor()).To(Equal(ketoclient.Glob))
+
+			_, err := client.UpsertOryAccessControlPolicy(&ketoclient.UpsertORYAccessPolicyRequest{
+				ORYAccessControlPolicy: ketoclient.ORYAccessControlPolicy{
+					ID:        "policy1",
+					Subjects:  []string{"user:*"},
+					Actions:   []string{"delete"},
+					Resources: []string{"blog1:post:*"},
+					Effect:    ketoclient.Allow,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := client.AllowedOryAccessControlPolicy(&ketoclient.AllowedORYAccessControlPolicyRequest{
+				Subject:  "user:snake-eyes",
+				Action:   "delete",
+				Resource: "blog1:post:33",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Allowed).To(BeTrue())
+
+			policies, err := client.ListOryAccessControlPolicy(&ketoclient.ListORYAccessPolicyRequest{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policies.Policies).To(HaveLen(1))
+			exact, err := keto.Client().ListOryAccessControlPolicy(ketoclient.Exact, &ketoclient.ListORYAccessPolicyRequest{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exact.Policies).To(BeEmpty())
+
+			Expect(client.DeleteOryAccessControlPolicy("policy1")).To(Succeed())
+			_, err = client.GetOryAccessControlPolicy("policy1")
+			Expect(err).To(Equal(ketoclient.ErrNotFound))
+		})
+
+		It("should keep the flavor in the views bound to a context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			client := keto.Client().Flavor(ketoclient.Regex).WithContext(ctx)
+			Expect(client.Flavor()).To(Equal(ketoclient.Regex))
+			_, err := client.GetOryAccessControlRole("role1")
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		})
+
+		It("should fail the calls of an unknown flavor", func() {
+			_, err := keto.Client().Flavor("wildcard").GetOryAccessControlRole("role1")
+			Expect(errors.Is(err, ketoclient.ErrInvalidRequest)).To(BeTrue())
+			Expect(keto.Requests()).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,87 @@
+package ketoclient
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/lab259/errors/v2"
+)
+
+// ErrInvalidResult is returned when an interceptor replaces the result of an
+// operation by a value of another type, or by nil for an operation that has a
+// result.
+var ErrInvalidResult = errors.New("interceptor returned an invalid result")
+
+// Invocation is a request of an operation going through the interceptors.
+type Invocation struct {
+	// Operation is the name of the client method, `GetOryAccessControlPolicy`
+	// for instance.
+	Operation string
+
+	// Flavor is the flavor of the operation. It is empty for the health and
+	// version endpoints.
+	Flavor Flavor
+
+	// Category is the category of the operation.
+	Category Category
+
+	// Request is the outgoing request. Interceptors may change it, adding
+	// headers or rewriting its URL, or replace it. The credentials are added
+	// after the interceptors.
+	Request *http.Request
+}
+
+// Handler performs an invocation, returning the decoded result of the
+// operation or its error.
+//
+// The results are the values returned by the client methods (e.g.
+// `*GetORYAccessPolicyResponseOK`), or nil for the operations that only
+// return an error.
+type Handler func(invocation *Invocation) (interface{}, error)
+
+// Interceptor wraps the invocations of the operations. It calls `next` to
+// go on with the invocation, and can change the result or the error it
+// returns. Returning without calling `next` short-circuits the invocation:
+// no request is sent and the result, which must be of the type the operation
+// returns and not nil unless the operation only returns an error, is given to
+// the caller.
+type Interceptor func(invocation *Invocation, next Handler) (interface{}, error)
+
+// intercept passes the invocation through the interceptors, in the order they
+// were given: the first interceptor sees the invocation first and its result
+// last.
+func (client *Client) intercept(invocation *Invocation, handler Handler) (interface{}, error) {
+	for i := len(client.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := client.interceptors[i], handler
+		handler = func(invocation *Invocation) (interface{}, error) {
+			return interceptor(invocation, next)
+		}
+	}
+	return handler(invocation)
+}
+
+// resultAs converts the result of an invocation to the type returned by the
+// operation. Only the operations without result, whose type is
+// `interface{}`, accept a nil result.
+func resultAs[T any](invocation *Invocation, result interface{}) (T, error) {
+	var zero T
+	if result == nil && interface{}(zero) == nil {
+		return zero, nil
+	}
+	r, ok := result.(T)
+	if ok && !isNil(r) {
+		return r, nil
+	}
+	got := fmt.Sprintf("%T", result)
+	if result == nil || isNil(result) {
+		got = "nil"
+	}
+	return zero, errors.Wrap(ErrInvalidResult, errors.Message(fmt.Sprintf("%s returns %T, got %s", invocation.Operation, zero, got)))
+}
+
+// isNil reports whether the value is a nil pointer.
+func isNil(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
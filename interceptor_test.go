@@ -0,0 +1,141 @@
+package ketoclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/lab259/errors/v2"
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Interceptors", func() {
+	var keto *fakeKeto
+
+	BeforeEach(func() {
+		keto = newFakeKeto()
+		keto.AddPolicies(ketoclient.Exact, ketoclient.ORYAccessControlPolicy{
+			ID:        "policy1",
+			Subjects:  []string{"user:snake-eyes"},
+			Actions:   []string{"delete"},
+			Resources: []string{"blog1:post:33"},
+			Effect:    ketoclient.Allow,
+		})
+	})
+
+	AfterEach(func() {
+		keto.Close()
+	})
+
+	It("should run the interceptors in the order they were given", func() {
+		var events []string
+		named := func(name string) ketoclient.Interceptor {
+			return func(invocation *ketoclient.Invocation, next ketoclient.Handler) (interface{}, error) {
+				events = append(events, fmt.Sprintf("%s before %s %s %s", name, invocation.Operation, invocation.Flavor, invocation.Request.Method))
+				result, err := next(invocation)
+				events = append(events, fmt.Sprintf("%s after %T %v", name, result, err))
+				return result, err
+			}
+		}
+		client := keto.Client(ketoclient.WithInterceptors(named("a")), ketoclient.WithInterceptors(named("b")))
+
+		_, err := client.GetOryAccessControlPolicy(ketoclient.Exact, "policy1")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.GetOryAccessControlPolicy(ketoclient.Exact, "unknown")
+		Expect(err).To(Equal(ketoclient.ErrNotFound))
+
+		Expect(events).To(Equal([]string{
+			"a before GetOryAccessControlPolicy exact GET",
+			"b before GetOryAccessControlPolicy exact GET",
+			"b after *ketoclient.GetORYAccessPolicyResponseOK <nil>",
+			"a after *ketoclient.GetORYAccessPolicyResponseOK <nil>",
+			"a before GetOryAccessControlPolicy exact GET",
+			"b before GetOryAccessControlPolicy exact GET",
+			"b after <nil> policy not found",
+			"a after <nil> policy not found",
+		}))
+	})
+
+	It("should let the interceptors change the outgoing request", func() {
+		headers := make(chan http.Header, 1)
+		sidecar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers <- r.Header
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer sidecar.Close()
+		sidecarURL, err := url.Parse(sidecar.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		client := keto.Client(ketoclient.WithInterceptors(func(invocation *ketoclient.Invocation, next ketoclient.Handler) (interface{}, error) {
+			invocation.Request.Header.Set("X-Tenant", "blog1")
+			invocation.Request.URL.Host = sidecarURL.Host
+			return next(invocation)
+		}))
+		Expect(client.DeleteOryAccessControlPolicy(ketoclient.Exact, "policy1")).To(Succeed())
+
+		Expect((<-headers).Get("X-Tenant")).To(Equal("blog1"))
+		Expect(keto.Requests()).To(BeZero())
+	})
+
+	It("should short-circuit the invocation", func() {
+		cached := &ketoclient.GetORYAccessPolicyResponseOK{}
+		cached.Policy.ID = "cached"
+		client := keto.Client(ketoclient.WithInterceptors(func(invocation *ketoclient.Invocation, next ketoclient.Handler) (interface{}, error) {
+			if invocation.Operation == "GetOryAccessControlPolicy" {
+				return cached, nil
+			}
+			return next(invocation)
+		}))
+
+		response, err := client.GetOryAccessControlPolicy(ketoclient.Exact, "policy1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).To(BeIdenticalTo(cached))
+		Expect(keto.Requests()).To(BeZero())
+	})
+
+	It("should fail when an interceptor returns a result of another type", func() {
+		client := keto.Client(ketoclient.WithInterceptors(func(invocation *ketoclient.Invocation, next ketoclient.Handler) (interface{}, error) {
+			return "allowed", nil
+		}))
+
+		_, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: "blog1:post:33",
+		})
+		Expect(errors.Is(err, ketoclient.ErrInvalidResult)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("AllowedOryAccessControlPolicy returns *ketoclient.AllowedORYAccessControlPolicyResponse, got string"))
+	})
+
+	It("should fail when an interceptor short-circuits an operation with a nil result", func() {
+		request := &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: "blog1:post:33",
+		}
+		for _, result := range []interface{}{nil, (*ketoclient.AllowedORYAccessControlPolicyResponse)(nil)} {
+			result := result
+			client := keto.Client(
+				ketoclient.WithStaleOnError(ketoclient.StaleOnError{}),
+				ketoclient.WithCheckDeduplication(),
+				ketoclient.WithInterceptors(func(invocation *ketoclient.Invocation, next ketoclient.Handler) (interface{}, error) {
+					return result, nil
+				}),
+			)
+
+			response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request)
+			Expect(errors.Is(err, ketoclient.ErrInvalidResult)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("AllowedOryAccessControlPolicy returns *ketoclient.AllowedORYAccessControlPolicyResponse, got nil"))
+			Expect(response).To(BeNil())
+		}
+
+		client := keto.Client(ketoclient.WithInterceptors(func(invocation *ketoclient.Invocation, next ketoclient.Handler) (interface{}, error) {
+			return nil, nil
+		}))
+		Expect(client.DeleteOryAccessControlPolicy(ketoclient.Exact, "policy1")).To(Succeed())
+		Expect(keto.Requests()).To(BeZero())
+	})
+})
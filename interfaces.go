@@ -0,0 +1,49 @@
+package ketoclient
+
+// Checker checks the authorization requests against the ORY Access Control
+// Policies.
+type Checker interface {
+	AllowedOryAccessControlPolicy(flavor Flavor, request *AllowedORYAccessControlPolicyRequest, opts ...CallOption) (*AllowedORYAccessControlPolicyResponse, error)
+}
+
+// PolicyStore manages the ORY Access Control Policies.
+type PolicyStore interface {
+	UpsertOryAccessControlPolicy(flavor Flavor, request *UpsertORYAccessPolicyRequest, opts ...CallOption) (*UpsertORYAccessPolicyResponseOK, error)
+	ListOryAccessControlPolicy(flavor Flavor, request *ListORYAccessPolicyRequest, opts ...CallOption) (*ListORYAccessPolicyResponseOK, error)
+	GetOryAccessControlPolicy(flavor Flavor, id string, opts ...CallOption) (*GetORYAccessPolicyResponseOK, error)
+	DeleteOryAccessControlPolicy(flavor Flavor, id string, opts ...CallOption) error
+}
+
+// RoleStore manages the ORY Access Control Roles and their members.
+type RoleStore interface {
+	UpsertOryAccessControlRole(flavor Flavor, request *UpsertORYAccessRoleRequest, opts ...CallOption) (*UpsertORYAccessRoleResponseOK, error)
+	ListOryAccessControlRole(flavor Flavor, request *ListORYAccessRoleRequest, opts ...CallOption) (*ListORYAccessRoleResponseOK, error)
+	GetOryAccessControlRole(flavor Flavor, id string, opts ...CallOption) (*GetORYAccessRoleResponseOK, error)
+	DeleteOryAccessControlRole(flavor Flavor, id string, opts ...CallOption) error
+	AddMembersOryAccessControlRole(flavor Flavor, id string, request *AddMembersORYAccessRoleRequest, opts ...CallOption) (*AddMembersORYAccessRoleResponseOK, error)
+	RemoveMemberOryAccessControlRole(flavor Flavor, id, member string, opts ...CallOption) error
+}
+
+// HealthChecker reports the health and the version of the server.
+type HealthChecker interface {
+	HealthAlive(opts ...CallOption) (*HealthAliveResponse, error)
+	HealthReadness(opts ...CallOption) (*HealthReadnessResponse, error)
+	Version(opts ...CallOption) (*VersionResponse, error)
+}
+
+// API is the whole API of the server, implemented by `Client`. The mocks of
+// the `ketomock` package implement it as well.
+type API interface {
+	Checker
+	PolicyStore
+	RoleStore
+	HealthChecker
+}
+
+var (
+	_ API = (*Client)(nil)
+
+	_ Checker     = (*TenantClient)(nil)
+	_ PolicyStore = (*TenantClient)(nil)
+	_ RoleStore   = (*TenantClient)(nil)
+)
@@ -0,0 +1,317 @@
+// Package ketofixture records the interactions of a client with a live Keto
+// server into golden files, and replays them so the tests can run without the
+// server once the golden files are recorded.
+//
+//	transport, err := ketofixture.New("testdata/fixtures/allowed.json", ketofixture.ModeFromEnv(), nil)
+//	client := ketoclient.New(ketoclient.WithURL(u), ketoclient.WithTransport(transport))
+//	// ... the scenario
+//	err = transport.Save()
+//
+// The requests are matched on their method, their path and query, and their
+// JSON body, whose formatting and key order are ignored. The hosts and the
+// headers of the requests are not recorded, so the fixtures do not leak the
+// credentials and replay whatever the URL of the client.
+package ketofixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lab259/errors/v2"
+)
+
+// Mode is how a `Transport` handles the requests.
+type Mode string
+
+const (
+	// Live sends the requests to the server, without recording them.
+	Live Mode = "live"
+
+	// Replay answers the requests with the recorded interactions, without
+	// any network access.
+	Replay Mode = "replay"
+
+	// Record sends the requests to the server and records the interactions.
+	Record Mode = "record"
+)
+
+// EnvMode is the environment variable read by `ModeFromEnv`.
+const EnvMode = "KETO_FIXTURES"
+
+var (
+	// ErrNoInteraction is returned when replaying a request that matches no
+	// recorded interaction left.
+	ErrNoInteraction = errors.New("no recorded interaction matches the request")
+
+	// ErrNoFixture is returned when replaying a golden file that does not
+	// exist.
+	ErrNoFixture = errors.New("fixture not recorded")
+)
+
+// ModeFromEnv returns the mode named by the `KETO_FIXTURES` environment
+// variable, `record` or `replay`, and `Live` otherwise.
+func ModeFromEnv() Mode {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(os.Getenv(EnvMode)))); mode {
+	case Record, Replay:
+		return mode
+	}
+	return Live
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method string `json:"method"`
+
+	// URI is the path of the request followed by its query, whose parameters
+	// are sorted.
+	URI string `json:"uri"`
+
+	// Body is the normalized JSON body of the request, if any.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// Response is a recorded response. Its body is kept as JSON when it is JSON,
+// so the golden files are easy to read, or as text otherwise.
+type Response struct {
+	StatusCode int             `json:"status_code"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	Text       string          `json:"text,omitempty"`
+}
+
+// Interaction is a request and the response of the server.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Transport is an `http.RoundTripper` recording or replaying the
+// interactions of a golden file. It is safe for concurrent use.
+type Transport struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// New creates a transport for the golden file. In `Replay` mode the file is
+// loaded, failing with `ErrNoFixture` when it does not exist. In `Record`
+// and `Live` modes the requests are sent through the transport,
+// `http.DefaultTransport` when nil.
+func New(path string, mode Mode, transport http.RoundTripper) (*Transport, error) {
+	t := &Transport{
+		path:      path,
+		mode:      mode,
+		transport: transport,
+	}
+	if t.transport == nil {
+		t.transport = http.DefaultTransport
+	}
+	if mode != Replay {
+		t.interactions = make([]Interaction, 0)
+		return t, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, errors.Wrap(ErrNoFixture, errors.Message(path+", record it with "+EnvMode+"=record"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &t.interactions); err != nil {
+		return nil, errors.Wrap(err, errors.Message(path))
+	}
+	// The golden files are indented, and may be edited by hand.
+	for i := range t.interactions {
+		if normalized, ok := normalize(t.interactions[i].Request.Body); ok {
+			t.interactions[i].Request.Body = normalized
+		}
+	}
+	t.used = make([]bool, len(t.interactions))
+	return t, nil
+}
+
+// Mode returns the mode of the transport.
+func (t *Transport) Mode() Mode {
+	return t.mode
+}
+
+// RoundTrip implements `http.RoundTripper`.
+func (t *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if t.mode == Live {
+		return t.transport.RoundTrip(request)
+	}
+	recorded, err := newRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	if t.mode == Record {
+		return t.record(request, recorded)
+	}
+	return t.replay(request, recorded)
+}
+
+// replay answers with the first interaction matching the request not used
+// yet, so a scenario repeating a request gets the responses in the order they
+// were recorded.
+func (t *Transport) replay(request *http.Request, recorded Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, interaction := range t.interactions {
+		if t.used[i] || !interaction.Request.matches(recorded) {
+			continue
+		}
+		t.used[i] = true
+		return interaction.Response.build(request), nil
+	}
+	return nil, errors.Wrap(ErrNoInteraction, errors.Message(recorded.Method+" "+recorded.URI))
+}
+
+func (t *Transport) record(request *http.Request, recorded Request) (*http.Response, error) {
+	response, err := t.transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction := Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: response.StatusCode,
+			Header:     response.Header.Clone(),
+		},
+	}
+	// The date would change the golden files at every recording.
+	interaction.Response.Header.Del("Date")
+	if normalized, ok := normalize(body); ok {
+		interaction.Response.Body = normalized
+	} else {
+		interaction.Response.Text = string(body)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.interactions = append(t.interactions, interaction)
+	t.used = append(t.used, true)
+	return response, nil
+}
+
+// Unused returns the recorded interactions not replayed yet. After a
+// deterministic scenario there is none.
+func (t *Transport) Unused() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var unused []Interaction
+	for i, interaction := range t.interactions {
+		if !t.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// Save writes the recorded interactions to the golden file, creating its
+// directory when needed. It only does so in `Record` mode.
+func (t *Transport) Save() error {
+	if t.mode != Record {
+		return nil
+	}
+	t.mu.Lock()
+	data, err := json.MarshalIndent(t.interactions, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, append(data, '\n'), 0644)
+}
+
+func newRequest(request *http.Request) (Request, error) {
+	recorded := Request{
+		Method: request.Method,
+		URI:    request.URL.EscapedPath(),
+	}
+	if query := request.URL.Query(); len(query) > 0 {
+		recorded.URI += "?" + query.Encode()
+	}
+	if request.Body == nil || request.Body == http.NoBody {
+		return recorded, nil
+	}
+
+	body, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return recorded, err
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	if normalized, ok := normalize(body); ok {
+		recorded.Body = normalized
+	} else if len(body) > 0 {
+		recorded.Body, _ = json.Marshal(string(body))
+	}
+	return recorded, nil
+}
+
+func (r Request) matches(other Request) bool {
+	return r.Method == other.Method && r.URI == other.URI && bytes.Equal(r.Body, other.Body)
+}
+
+func (r Response) build(request *http.Request) *http.Response {
+	body := []byte(r.Text)
+	if len(r.Body) > 0 {
+		body = r.Body
+	}
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}
+}
+
+// normalize compacts the JSON document, sorting the keys of its objects. It
+// reports false when the data is not JSON.
+func normalize(data []byte) (json.RawMessage, bool) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil || decoder.More() {
+		return nil, false
+	}
+	normalized, err := json.Marshal(document)
+	if err != nil {
+		return nil, false
+	}
+	return normalized, true
+}
@@ -0,0 +1,11 @@
+package ketofixture_test
+
+import (
+	"testing"
+
+	"github.com/lab259/ory-keto-client/ginkgotest"
+)
+
+func TestPackage(t *testing.T) {
+	ginkgotest.Init("Keto Fixture Test Suite", t)
+}
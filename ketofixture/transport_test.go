@@ -0,0 +1,225 @@
+package ketofixture_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/lab259/errors/v2"
+	ketoclient "github.com/lab259/ory-keto-client"
+	"github.com/lab259/ory-keto-client/ketofixture"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var breakerPrefixes int64
+
+func newClient(u *url.URL, transport http.RoundTripper) *ketoclient.Client {
+	return ketoclient.New(
+		ketoclient.WithURL(u),
+		ketoclient.WithTransport(transport),
+		ketoclient.WithBreakerPrefix(fmt.Sprintf("ketofixture-test-%d", atomic.AddInt64(&breakerPrefixes, 1))),
+	)
+}
+
+var _ = Describe("Transport", func() {
+	var (
+		dir      string
+		requests int64
+		server   *httptest.Server
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "ketofixture")
+		Expect(err).ToNot(HaveOccurred())
+
+		requests = 0
+		var policies []ketoclient.ORYAccessControlPolicy
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&requests, 1)
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.Method == http.MethodPut:
+				var policy ketoclient.ORYAccessControlPolicy
+				Expect(json.NewDecoder(r.Body).Decode(&policy)).To(Succeed())
+				policies = append(policies, policy)
+				Expect(json.NewEncoder(w).Encode(policy)).To(Succeed())
+			case strings.HasSuffix(r.URL.Path, "/policies"):
+				Expect(json.NewEncoder(w).Encode(policies)).To(Succeed())
+			case r.URL.Path == "/version":
+				io.WriteString(w, `{"version": "v0.3.3-sandbox+oryOS.12"}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				io.WriteString(w, `{"code": 404, "message": "not found"}`)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	scenario := func(client *ketoclient.Client) {
+		list, err := client.ListOryAccessControlPolicy(ketoclient.Exact, &ketoclient.ListORYAccessPolicyRequest{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list.Policies).To(BeEmpty())
+
+		_, err = client.UpsertOryAccessControlPolicy(ketoclient.Exact, &ketoclient.UpsertORYAccessPolicyRequest{
+			ORYAccessControlPolicy: ketoclient.ORYAccessControlPolicy{
+				ID:        "id1",
+				Subjects:  []string{"alice"},
+				Actions:   []string{"read"},
+				Resources: []string{"blog"},
+				Effect:    ketoclient.Allow,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		list, err = client.ListOryAccessControlPolicy(ketoclient.Exact, &ketoclient.ListORYAccessPolicyRequest{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list.Policies).To(HaveLen(1))
+
+		_, err = client.GetOryAccessControlPolicy(ketoclient.Exact, "id2")
+		Expect(errors.Is(err, ketoclient.ErrNotFound)).To(BeTrue())
+
+		version, err := client.Version()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(version.Version).To(Equal("v0.3.3-sandbox+oryOS.12"))
+	}
+
+	It("should replay a recorded scenario offline", func() {
+		path := filepath.Join(dir, "fixtures", "scenario.json")
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		recorder, err := ketofixture.New(path, ketofixture.Record, nil)
+		Expect(err).ToNot(HaveOccurred())
+		scenario(newClient(u, recorder))
+		Expect(recorder.Save()).To(Succeed())
+		Expect(atomic.LoadInt64(&requests)).To(BeEquivalentTo(5))
+		server.Close()
+
+		player, err := ketofixture.New(path, ketofixture.Replay, nil)
+		Expect(err).ToNot(HaveOccurred())
+		scenario(newClient(&url.URL{Scheme: "http", Host: "keto.invalid:4466"}, player))
+		Expect(player.Unused()).To(BeEmpty())
+	})
+
+	It("should write readable golden files without the dates", func() {
+		path := filepath.Join(dir, "version.json")
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		recorder, err := ketofixture.New(path, ketofixture.Record, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = newClient(u, recorder).Version()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.Save()).To(Succeed())
+
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		var interactions []ketofixture.Interaction
+		Expect(json.Unmarshal(data, &interactions)).To(Succeed())
+		Expect(interactions).To(HaveLen(1))
+		Expect(interactions[0].Request).To(Equal(ketofixture.Request{Method: "GET", URI: "/version"}))
+		Expect(interactions[0].Response.StatusCode).To(Equal(http.StatusOK))
+		Expect(interactions[0].Response.Header).ToNot(HaveKey("Date"))
+		Expect(string(data)).To(ContainSubstring(`"version": "v0.3.3-sandbox+oryOS.12"`))
+	})
+
+	It("should match the requests on their normalized JSON body", func() {
+		path := filepath.Join(dir, "body.json")
+		Expect(os.WriteFile(path, []byte(`[
+			{
+				"request": {
+					"method": "PUT",
+					"uri": "/engines/acp/ory/exact/policies?a=1&b=2",
+					"body": {"id": "id1", "subjects": ["alice"], "effect": "allow"}
+				},
+				"response": {"status_code": 200, "text": "first"}
+			},
+			{
+				"request": {
+					"method": "PUT",
+					"uri": "/engines/acp/ory/exact/policies?a=1&b=2",
+					"body": {"id": "id1", "subjects": ["alice"], "effect": "allow"}
+				},
+				"response": {"status_code": 500, "text": "second"}
+			}
+		]`), 0644)).To(Succeed())
+		player, err := ketofixture.New(path, ketofixture.Replay, nil)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{Transport: player}
+
+		put := func(uri, body string) (*http.Response, error) {
+			request, err := http.NewRequest(http.MethodPut, "http://keto.invalid"+uri, strings.NewReader(body))
+			Expect(err).ToNot(HaveOccurred())
+			return client.Do(request)
+		}
+
+		response, err := put("/engines/acp/ory/exact/policies?b=2&a=1", `{"effect":"allow",  "subjects":["alice"], "id":"id1"}`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(response.Status).To(Equal("200 OK"))
+		body, err := io.ReadAll(response.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("first"))
+
+		_, err = put("/engines/acp/ory/exact/policies?a=1&b=2", `{"id":"id1","subjects":["bob"],"effect":"allow"}`)
+		Expect(errors.Is(err, ketofixture.ErrNoInteraction)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("PUT /engines/acp/ory/exact/policies?a=1&b=2"))
+		Expect(player.Unused()).To(HaveLen(1))
+
+		response, err = put("/engines/acp/ory/exact/policies?a=1&b=2", `{"id":"id1","subjects":["alice"],"effect":"allow"}`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+		Expect(player.Unused()).To(BeEmpty())
+
+		_, err = put("/engines/acp/ory/exact/policies?a=1&b=2", `{"id":"id1","subjects":["alice"],"effect":"allow"}`)
+		Expect(errors.Is(err, ketofixture.ErrNoInteraction)).To(BeTrue())
+	})
+
+	It("should fail replaying a fixture not recorded", func() {
+		_, err := ketofixture.New(filepath.Join(dir, "missing.json"), ketofixture.Replay, nil)
+		Expect(errors.Is(err, ketofixture.ErrNoFixture)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("KETO_FIXTURES=record"))
+	})
+
+	It("should read the mode from the environment", func() {
+		if mode, ok := os.LookupEnv(ketofixture.EnvMode); ok {
+			defer os.Setenv(ketofixture.EnvMode, mode)
+		} else {
+			defer os.Unsetenv(ketofixture.EnvMode)
+		}
+		Expect(os.Unsetenv(ketofixture.EnvMode)).To(Succeed())
+		Expect(ketofixture.ModeFromEnv()).To(Equal(ketofixture.Live))
+		Expect(os.Setenv(ketofixture.EnvMode, " Record ")).To(Succeed())
+		Expect(ketofixture.ModeFromEnv()).To(Equal(ketofixture.Record))
+		Expect(os.Setenv(ketofixture.EnvMode, "replay")).To(Succeed())
+		Expect(ketofixture.ModeFromEnv()).To(Equal(ketofixture.Replay))
+	})
+
+	It("should send the requests without recording them in live mode", func() {
+		path := filepath.Join(dir, "live.json")
+		transport, err := ketofixture.New(path, ketofixture.Live, nil)
+		Expect(err).ToNot(HaveOccurred())
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(newClient(u, transport).CheckVersion()).To(Succeed())
+		Expect(atomic.LoadInt64(&requests)).To(Equal(int64(1)))
+		Expect(transport.Unused()).To(BeEmpty())
+		Expect(transport.Save()).To(Succeed())
+		_, err = os.Stat(path)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,11 @@
+package ketomock_test
+
+import (
+	"testing"
+
+	"github.com/lab259/ory-keto-client/ginkgotest"
+)
+
+func TestPackage(t *testing.T) {
+	ginkgotest.Init("Keto Mock Test Suite", t)
+}
@@ -0,0 +1,219 @@
+// Package ketomock provides a mock of the `ketoclient` interfaces that
+// records its calls and answers with configurable responses.
+package ketomock
+
+import (
+	"sync"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+)
+
+// DefaultVersion is the version answered by `Client.Version` when
+// `VersionFunc` is not set.
+const DefaultVersion = "v0.3.3-sandbox"
+
+// Call is a call received by the mock.
+type Call struct {
+	// Method is the name of the method, `GetOryAccessControlPolicy` for
+	// instance.
+	Method string
+
+	// Flavor is the flavor of the call. It is empty for the health methods.
+	Flavor ketoclient.Flavor
+
+	// Args are the other arguments of the call, in order, the call options
+	// left out.
+	Args []interface{}
+
+	// Options are the call options.
+	Options []ketoclient.CallOption
+}
+
+// Client is a mock of `ketoclient.API`, so it can replace a
+// `*ketoclient.Client` behind any of its interfaces. It is safe for
+// concurrent use.
+//
+// Every method records its call and, when the function of the same name
+// suffixed by `Func` is set, returns what it returns. Otherwise the method
+// answers as an empty server would: checks are denied, the policies and roles
+// are not found, listings are empty, upserts return what they were given and
+// deletes succeed.
+//
+// The functions have the same signatures as the methods of
+// `*ketoclient.Client`, so they can also be delegated to a real client.
+type Client struct {
+	AllowedOryAccessControlPolicyFunc    func(flavor ketoclient.Flavor, request *ketoclient.AllowedORYAccessControlPolicyRequest, opts ...ketoclient.CallOption) (*ketoclient.AllowedORYAccessControlPolicyResponse, error)
+	UpsertOryAccessControlPolicyFunc     func(flavor ketoclient.Flavor, request *ketoclient.UpsertORYAccessPolicyRequest, opts ...ketoclient.CallOption) (*ketoclient.UpsertORYAccessPolicyResponseOK, error)
+	ListOryAccessControlPolicyFunc       func(flavor ketoclient.Flavor, request *ketoclient.ListORYAccessPolicyRequest, opts ...ketoclient.CallOption) (*ketoclient.ListORYAccessPolicyResponseOK, error)
+	GetOryAccessControlPolicyFunc        func(flavor ketoclient.Flavor, id string, opts ...ketoclient.CallOption) (*ketoclient.GetORYAccessPolicyResponseOK, error)
+	DeleteOryAccessControlPolicyFunc     func(flavor ketoclient.Flavor, id string, opts ...ketoclient.CallOption) error
+	UpsertOryAccessControlRoleFunc       func(flavor ketoclient.Flavor, request *ketoclient.UpsertORYAccessRoleRequest, opts ...ketoclient.CallOption) (*ketoclient.UpsertORYAccessRoleResponseOK, error)
+	ListOryAccessControlRoleFunc         func(flavor ketoclient.Flavor, request *ketoclient.ListORYAccessRoleRequest, opts ...ketoclient.CallOption) (*ketoclient.ListORYAccessRoleResponseOK, error)
+	GetOryAccessControlRoleFunc          func(flavor ketoclient.Flavor, id string, opts ...ketoclient.CallOption) (*ketoclient.GetORYAccessRoleResponseOK, error)
+	DeleteOryAccessControlRoleFunc       func(flavor ketoclient.Flavor, id string, opts ...ketoclient.CallOption) error
+	AddMembersOryAccessControlRoleFunc   func(flavor ketoclient.Flavor, id string, request *ketoclient.AddMembersORYAccessRoleRequest, opts ...ketoclient.CallOption) (*ketoclient.AddMembersORYAccessRoleResponseOK, error)
+	RemoveMemberOryAccessControlRoleFunc func(flavor ketoclient.Flavor, id, member string, opts ...ketoclient.CallOption) error
+	HealthAliveFunc                      func(opts ...ketoclient.CallOption) (*ketoclient.HealthAliveResponse, error)
+	HealthReadnessFunc                   func(opts ...ketoclient.CallOption) (*ketoclient.HealthReadnessResponse, error)
+	VersionFunc                          func(opts ...ketoclient.CallOption) (*ketoclient.VersionResponse, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ ketoclient.API = (*Client)(nil)
+
+func (m *Client) record(method string, flavor ketoclient.Flavor, opts []ketoclient.CallOption, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{
+		Method:  method,
+		Flavor:  flavor,
+		Args:    args,
+		Options: opts,
+	})
+}
+
+// Calls returns the calls received by the mock, in order.
+func (m *Client) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	calls := make([]Call, len(m.calls))
+	copy(calls, m.calls)
+	return calls
+}
+
+// CallsTo returns the calls of the method received by the mock, in order.
+func (m *Client) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var calls []Call
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the calls received by the mock. The functions are kept.
+func (m *Client) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+func (m *Client) AllowedOryAccessControlPolicy(flavor ketoclient.Flavor, request *ketoclient.AllowedORYAccessControlPolicyRequest, opts ...ketoclient.CallOption) (*ketoclient.AllowedORYAccessControlPolicyResponse, error) {
+	m.record("AllowedOryAccessControlPolicy", flavor, opts, request)
+	if m.AllowedOryAccessControlPolicyFunc != nil {
+		return m.AllowedOryAccessControlPolicyFunc(flavor, request, opts...)
+	}
+	return &ketoclient.AllowedORYAccessControlPolicyResponse{Allowed: false}, nil
+}
+
+func (m *Client) UpsertOryAccessControlPolicy(flavor ketoclient.Flavor, request *ketoclient.UpsertORYAccessPolicyRequest, opts ...ketoclient.CallOption) (*ketoclient.UpsertORYAccessPolicyResponseOK, error) {
+	m.record("UpsertOryAccessControlPolicy", flavor, opts, request)
+	if m.UpsertOryAccessControlPolicyFunc != nil {
+		return m.UpsertOryAccessControlPolicyFunc(flavor, request, opts...)
+	}
+	policy := request.ORYAccessControlPolicy
+	return &ketoclient.UpsertORYAccessPolicyResponseOK{ORYAccessControlPolicy: &policy}, nil
+}
+
+func (m *Client) ListOryAccessControlPolicy(flavor ketoclient.Flavor, request *ketoclient.ListORYAccessPolicyRequest, opts ...ketoclient.CallOption) (*ketoclient.ListORYAccessPolicyResponseOK, error) {
+	m.record("ListOryAccessControlPolicy", flavor, opts, request)
+	if m.ListOryAccessControlPolicyFunc != nil {
+		return m.ListOryAccessControlPolicyFunc(flavor, request, opts...)
+	}
+	return &ketoclient.ListORYAccessPolicyResponseOK{Policies: []ketoclient.ORYAccessControlPolicy{}}, nil
+}
+
+func (m *Client) GetOryAccessControlPolicy(flavor ketoclient.Flavor, id string, opts ...ketoclient.CallOption) (*ketoclient.GetORYAccessPolicyResponseOK, error) {
+	m.record("GetOryAccessControlPolicy", flavor, opts, id)
+	if m.GetOryAccessControlPolicyFunc != nil {
+		return m.GetOryAccessControlPolicyFunc(flavor, id, opts...)
+	}
+	return nil, ketoclient.ErrNotFound
+}
+
+func (m *Client) DeleteOryAccessControlPolicy(flavor ketoclient.Flavor, id string, opts ...ketoclient.CallOption) error {
+	m.record("DeleteOryAccessControlPolicy", flavor, opts, id)
+	if m.DeleteOryAccessControlPolicyFunc != nil {
+		return m.DeleteOryAccessControlPolicyFunc(flavor, id, opts...)
+	}
+	return nil
+}
+
+func (m *Client) UpsertOryAccessControlRole(flavor ketoclient.Flavor, request *ketoclient.UpsertORYAccessRoleRequest, opts ...ketoclient.CallOption) (*ketoclient.UpsertORYAccessRoleResponseOK, error) {
+	m.record("UpsertOryAccessControlRole", flavor, opts, request)
+	if m.UpsertOryAccessControlRoleFunc != nil {
+		return m.UpsertOryAccessControlRoleFunc(flavor, request, opts...)
+	}
+	return &ketoclient.UpsertORYAccessRoleResponseOK{Role: request.Role}, nil
+}
+
+func (m *Client) ListOryAccessControlRole(flavor ketoclient.Flavor, request *ketoclient.ListORYAccessRoleRequest, opts ...ketoclient.CallOption) (*ketoclient.ListORYAccessRoleResponseOK, error) {
+	m.record("ListOryAccessControlRole", flavor, opts, request)
+	if m.ListOryAccessControlRoleFunc != nil {
+		return m.ListOryAccessControlRoleFunc(flavor, request, opts...)
+	}
+	return &ketoclient.ListORYAccessRoleResponseOK{Roles: []ketoclient.ORYAccessControlRole{}}, nil
+}
+
+func (m *Client) GetOryAccessControlRole(flavor ketoclient.Flavor, id string, opts ...ketoclient.CallOption) (*ketoclient.GetORYAccessRoleResponseOK, error) {
+	m.record("GetOryAccessControlRole", flavor, opts, id)
+	if m.GetOryAccessControlRoleFunc != nil {
+		return m.GetOryAccessControlRoleFunc(flavor, id, opts...)
+	}
+	return nil, ketoclient.ErrNotFound
+}
+
+func (m *Client) DeleteOryAccessControlRole(flavor ketoclient.Flavor, id string, opts ...ketoclient.CallOption) error {
+	m.record("DeleteOryAccessControlRole", flavor, opts, id)
+	if m.DeleteOryAccessControlRoleFunc != nil {
+		return m.DeleteOryAccessControlRoleFunc(flavor, id, opts...)
+	}
+	return nil
+}
+
+func (m *Client) AddMembersOryAccessControlRole(flavor ketoclient.Flavor, id string, request *ketoclient.AddMembersORYAccessRoleRequest, opts ...ketoclient.CallOption) (*ketoclient.AddMembersORYAccessRoleResponseOK, error) {
+	m.record("AddMembersOryAccessControlRole", flavor, opts, id, request)
+	if m.AddMembersOryAccessControlRoleFunc != nil {
+		return m.AddMembersOryAccessControlRoleFunc(flavor, id, request, opts...)
+	}
+	return &ketoclient.AddMembersORYAccessRoleResponseOK{
+		Role: ketoclient.ORYAccessControlRole{ID: id, Members: request.Members},
+	}, nil
+}
+
+func (m *Client) RemoveMemberOryAccessControlRole(flavor ketoclient.Flavor, id, member string, opts ...ketoclient.CallOption) error {
+	m.record("RemoveMemberOryAccessControlRole", flavor, opts, id, member)
+	if m.RemoveMemberOryAccessControlRoleFunc != nil {
+		return m.RemoveMemberOryAccessControlRoleFunc(flavor, id, member, opts...)
+	}
+	return nil
+}
+
+func (m *Client) HealthAlive(opts ...ketoclient.CallOption) (*ketoclient.HealthAliveResponse, error) {
+	m.record("HealthAlive", "", opts)
+	if m.HealthAliveFunc != nil {
+		return m.HealthAliveFunc(opts...)
+	}
+	return &ketoclient.HealthAliveResponse{Status: "ok"}, nil
+}
+
+func (m *Client) HealthReadness(opts ...ketoclient.CallOption) (*ketoclient.HealthReadnessResponse, error) {
+	m.record("HealthReadness", "", opts)
+	if m.HealthReadnessFunc != nil {
+		return m.HealthReadnessFunc(opts...)
+	}
+	return &ketoclient.HealthReadnessResponse{Status: "ok"}, nil
+}
+
+func (m *Client) Version(opts ...ketoclient.CallOption) (*ketoclient.VersionResponse, error) {
+	m.record("Version", "", opts)
+	if m.VersionFunc != nil {
+		return m.VersionFunc(opts...)
+	}
+	return &ketoclient.VersionResponse{Version: DefaultVersion}, nil
+}
@@ -0,0 +1,96 @@
+package ketomock_test
+
+import (
+	"github.com/lab259/errors/v2"
+	ketoclient "github.com/lab259/ory-keto-client"
+	"github.com/lab259/ory-keto-client/ketomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client", func() {
+	var mock *ketomock.Client
+
+	BeforeEach(func() {
+		mock = &ketomock.Client{}
+	})
+
+	It("should answer as an empty server by default", func() {
+		var checker ketoclient.Checker = mock
+		allowed, err := checker.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject: "alice", Action: "read", Resource: "blog",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowed.Allowed).To(BeFalse())
+
+		var policies ketoclient.PolicyStore = mock
+		_, err = policies.GetOryAccessControlPolicy(ketoclient.Exact, "policy")
+		Expect(errors.Is(err, ketoclient.ErrNotFound)).To(BeTrue())
+		list, err := policies.ListOryAccessControlPolicy(ketoclient.Exact, &ketoclient.ListORYAccessPolicyRequest{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list.Policies).To(BeEmpty())
+
+		var roles ketoclient.RoleStore = mock
+		role, err := roles.AddMembersOryAccessControlRole(ketoclient.Glob, "admins", &ketoclient.AddMembersORYAccessRoleRequest{
+			Members: []string{"alice"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(role.Role).To(Equal(ketoclient.ORYAccessControlRole{ID: "admins", Members: []string{"alice"}}))
+		Expect(roles.DeleteOryAccessControlRole(ketoclient.Glob, "admins")).To(Succeed())
+
+		var health ketoclient.HealthChecker = mock
+		version, err := health.Version()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(version.Version).To(Equal(ketomock.DefaultVersion))
+	})
+
+	It("should echo the upserted policy", func() {
+		policy := ketoclient.ORYAccessControlPolicy{ID: "policy", Subjects: []string{"alice"}}
+		response, err := mock.UpsertOryAccessControlPolicy(ketoclient.Exact, &ketoclient.UpsertORYAccessPolicyRequest{
+			ORYAccessControlPolicy: policy,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*response.ORYAccessControlPolicy).To(Equal(policy))
+	})
+
+	It("should answer with the configured functions", func() {
+		mock.AllowedOryAccessControlPolicyFunc = func(flavor ketoclient.Flavor, request *ketoclient.AllowedORYAccessControlPolicyRequest, opts ...ketoclient.CallOption) (*ketoclient.AllowedORYAccessControlPolicyResponse, error) {
+			return &ketoclient.AllowedORYAccessControlPolicyResponse{Allowed: request.Subject == "alice"}, nil
+		}
+		mock.DeleteOryAccessControlPolicyFunc = func(flavor ketoclient.Flavor, id string, opts ...ketoclient.CallOption) error {
+			return ketoclient.ErrNotFound
+		}
+
+		allowed, err := mock.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{Subject: "alice"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowed.Allowed).To(BeTrue())
+		allowed, err = mock.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{Subject: "bob"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowed.Allowed).To(BeFalse())
+
+		Expect(errors.Is(mock.DeleteOryAccessControlPolicy(ketoclient.Exact, "policy"), ketoclient.ErrNotFound)).To(BeTrue())
+	})
+
+	It("should record the calls", func() {
+		request := &ketoclient.AllowedORYAccessControlPolicyRequest{Subject: "alice"}
+		noCache := ketoclient.CallNoCache()
+		_, _ = mock.AllowedOryAccessControlPolicy(ketoclient.Regex, request, noCache)
+		Expect(mock.RemoveMemberOryAccessControlRole(ketoclient.Exact, "admins", "alice")).To(Succeed())
+		_, _ = mock.HealthAlive()
+
+		calls := mock.Calls()
+		Expect(calls).To(HaveLen(3))
+		Expect(calls[0].Method).To(Equal("AllowedOryAccessControlPolicy"))
+		Expect(calls[0].Flavor).To(Equal(ketoclient.Regex))
+		Expect(calls[0].Args).To(Equal([]interface{}{request}))
+		Expect(calls[0].Options).To(HaveLen(1))
+		Expect(calls[1].Args).To(Equal([]interface{}{"admins", "alice"}))
+		Expect(calls[2].Flavor).To(BeEmpty())
+
+		Expect(mock.CallsTo("RemoveMemberOryAccessControlRole")).To(HaveLen(1))
+		Expect(mock.CallsTo("Version")).To(BeEmpty())
+
+		mock.Reset()
+		Expect(mock.Calls()).To(BeEmpty())
+	})
+})
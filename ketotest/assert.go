@@ -0,0 +1,117 @@
+package ketotest
+
+import (
+	"fmt"
+)
+
+// TestingT is the part of `*testing.T` used by the assertions. It matches
+// `assert.TestingT` of testify.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// RequireT is the part of `*testing.T` used by the requirements. It matches
+// `require.TestingT` of testify.
+type RequireT interface {
+	TestingT
+	FailNow()
+}
+
+type helper interface {
+	Helper()
+}
+
+// AssertAllowed asserts that the checker allows the subject to perform the
+// action on the resource, as `BeAllowedTo` does. It returns whether the
+// assertion succeeded. As in testify, the optional message is a format
+// followed by its arguments. The options, `InFlavor` for instance, can be
+// given among them:
+//
+//	ketotest.AssertAllowed(t, tenant, "alice", "read", "blog:*", ketotest.InFlavor(ketoclient.Glob))
+func AssertAllowed(t TestingT, checker interface{}, subject, action, resource string, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+	return assertDecision(t, checker, subject, action, resource, true, msgAndArgs)
+}
+
+// AssertDenied asserts that the checker denies the subject to perform the
+// action on the resource, as `BeDeniedTo` does. See `AssertAllowed`.
+func AssertDenied(t TestingT, checker interface{}, subject, action, resource string, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+	return assertDecision(t, checker, subject, action, resource, false, msgAndArgs)
+}
+
+// RequireAllowed is `AssertAllowed` stopping the test when it fails.
+func RequireAllowed(t RequireT, checker interface{}, subject, action, resource string, msgAndArgs ...interface{}) {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+	if !assertDecision(t, checker, subject, action, resource, true, msgAndArgs) {
+		t.FailNow()
+	}
+}
+
+// RequireDenied is `AssertDenied` stopping the test when it fails.
+func RequireDenied(t RequireT, checker interface{}, subject, action, resource string, msgAndArgs ...interface{}) {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+	if !assertDecision(t, checker, subject, action, resource, false, msgAndArgs) {
+		t.FailNow()
+	}
+}
+
+func assertDecision(t TestingT, checker interface{}, subject, action, resource string, allowed bool, msgAndArgs []interface{}) bool {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+	o, msgAndArgs := splitOptions(msgAndArgs)
+	d := decide(checker, subject, action, resource, o)
+	var message string
+	switch {
+	case d.err != nil:
+		message = fmt.Sprintf("Failed to check %q to %q %q: %s", subject, action, resource, d.err)
+	case d.allowed != allowed:
+		message = d.message(allowed)
+	default:
+		return true
+	}
+	if extra := messageFromMsgAndArgs(msgAndArgs); extra != "" {
+		message += "\nMessage: " + extra
+	}
+	t.Errorf("%s", message)
+	return false
+}
+
+// splitOptions separates the options from the message and its arguments.
+func splitOptions(msgAndArgs []interface{}) (options, []interface{}) {
+	var opts []Option
+	rest := make([]interface{}, 0, len(msgAndArgs))
+	for _, arg := range msgAndArgs {
+		if opt, ok := arg.(Option); ok {
+			opts = append(opts, opt)
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return newOptions(opts), rest
+}
+
+func messageFromMsgAndArgs(msgAndArgs []interface{}) string {
+	switch len(msgAndArgs) {
+	case 0:
+		return ""
+	case 1:
+		if format, ok := msgAndArgs[0].(string); ok {
+			return format
+		}
+		return fmt.Sprintf("%+v", msgAndArgs[0])
+	}
+	if format, ok := msgAndArgs[0].(string); ok {
+		return fmt.Sprintf(format, msgAndArgs[1:]...)
+	}
+	return fmt.Sprint(msgAndArgs...)
+}
@@ -0,0 +1,78 @@
+package ketotest_test
+
+import (
+	"fmt"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+	"github.com/lab259/ory-keto-client/ketomock"
+	"github.com/lab259/ory-keto-client/ketotest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// recorder records the failures reported as a `*testing.T` would.
+type recorder struct {
+	errors  []string
+	stopped bool
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) FailNow() {
+	r.stopped = true
+}
+
+var _ = Describe("Assertions", func() {
+	var t *recorder
+
+	evaluator := ketoclient.NewEvaluator(ketoclient.Exact, policies, roles)
+
+	BeforeEach(func() {
+		t = &recorder{}
+	})
+
+	It("should pass silently", func() {
+		Expect(ketotest.AssertAllowed(t, evaluator, "alice", "write", "blog")).To(BeTrue())
+		Expect(ketotest.AssertDenied(t, evaluator, "mallory", "write", "blog")).To(BeTrue())
+		ketotest.RequireAllowed(t, evaluator, "alice", "read", "blog")
+		ketotest.RequireDenied(t, evaluator, "bob", "read", "blog")
+		Expect(t.errors).To(BeEmpty())
+		Expect(t.stopped).To(BeFalse())
+	})
+
+	It("should report the failures with the matching policies", func() {
+		Expect(ketotest.AssertAllowed(t, evaluator, "mallory", "write", "blog", "mallory is %s", "trusted")).To(BeFalse())
+		Expect(t.errors).To(HaveLen(1))
+		Expect(t.errors[0]).To(HavePrefix(`Expected "mallory" to be allowed to "write" "blog", but it is denied`))
+		Expect(t.errors[0]).To(ContainSubstring("banned (deny)"))
+		Expect(t.errors[0]).To(HaveSuffix("\nMessage: mallory is trusted"))
+		Expect(t.stopped).To(BeFalse())
+	})
+
+	It("should check in the flavor given among the message arguments", func() {
+		mock := &ketomock.Client{}
+		Expect(ketotest.AssertAllowed(t, mock, "alice", "read", "blog", ketotest.InFlavor(ketoclient.Regex), "alice is %s", "an editor")).To(BeFalse())
+		Expect(t.errors).To(Equal([]string{
+			"Expected \"alice\" to be allowed to \"read\" \"blog\", but it is denied\nMessage: alice is an editor",
+		}))
+		ketotest.RequireDenied(t, mock, "alice", "read", "blog", ketotest.InFlavor(ketoclient.Glob))
+		Expect(mock.Calls()).To(HaveLen(2))
+		Expect(mock.Calls()[0].Flavor).To(Equal(ketoclient.Regex))
+		Expect(mock.Calls()[1].Flavor).To(Equal(ketoclient.Glob))
+	})
+
+	It("should stop the test when a requirement fails", func() {
+		ketotest.RequireDenied(t, evaluator, "alice", "write", "blog")
+		Expect(t.errors).To(HaveLen(1))
+		Expect(t.stopped).To(BeTrue())
+	})
+
+	It("should report the checks that failed", func() {
+		Expect(ketotest.AssertDenied(t, 42, "alice", "write", "blog")).To(BeFalse())
+		Expect(t.errors).To(Equal([]string{
+			`Failed to check "alice" to "write" "blog": ketotest: cannot check the requests with int`,
+		}))
+	})
+})
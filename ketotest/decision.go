@@ -0,0 +1,159 @@
+// Package ketotest provides assertions of the authorization decisions, as
+// gomega matchers and as testify style helpers.
+//
+// The assertions check the requests against a `*ketoclient.Client`, a
+// `*ketoclient.FlavorClient`, a local `*ketoclient.Evaluator` or any other
+// `ketoclient.Checker`, the mocks of the `ketomock` package for instance.
+// When an assertion fails, the policies matching the request are printed,
+// unless the checker cannot tell them. `InFlavor` chooses the flavor of the
+// checks.
+package ketotest
+
+import (
+	"fmt"
+	"strings"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+)
+
+// Option configures an assertion.
+type Option func(*options)
+
+type options struct {
+	flavor ketoclient.Flavor
+}
+
+// InFlavor makes the assertion check the request in the flavor, instead of
+// the default flavor of a `*ketoclient.Client`, the flavor of a
+// `*ketoclient.FlavorClient` or `Exact` for the other checkers. An evaluator
+// decides in the flavor it was created with, so the assertion errors with
+// another one.
+func InFlavor(flavor ketoclient.Flavor) Option {
+	return func(o *options) {
+		o.flavor = flavor
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// or returns the flavor of the options, when given, or the default one.
+func (o options) or(flavor ketoclient.Flavor) ketoclient.Flavor {
+	if o.flavor != "" {
+		return o.flavor
+	}
+	return flavor
+}
+
+// decision is the outcome of a request checked by an assertion.
+type decision struct {
+	request *ketoclient.AllowedORYAccessControlPolicyRequest
+	allowed bool
+	err     error
+
+	// explain lists the policies matching the request. It is nil when the
+	// checker cannot explain its decisions.
+	explain func() (*ketoclient.Explanation, error)
+}
+
+// decide checks the request against the checker, in the flavor of the
+// options or, when none is given, the default flavor of the client, the
+// flavor of the flavor client and `Exact` for the other checkers but the
+// evaluators.
+func decide(checker interface{}, subject, action, resource string, o options) *decision {
+	d := &decision{
+		request: &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  subject,
+			Action:   action,
+			Resource: resource,
+		},
+	}
+	switch c := checker.(type) {
+	case *ketoclient.Evaluator:
+		if flavor := o.or(c.Flavor()); flavor != c.Flavor() {
+			d.err = fmt.Errorf("ketotest: the evaluator decides in the %s flavor, not in the %s one", c.Flavor(), flavor)
+			break
+		}
+		explanation, err := c.Explain(d.request)
+		if err != nil {
+			d.err = err
+			break
+		}
+		d.allowed = explanation.Allowed
+		d.explain = func() (*ketoclient.Explanation, error) {
+			return explanation, nil
+		}
+	case *ketoclient.Client:
+		flavor := o.or(c.DefaultFlavor())
+		d.check(c, flavor)
+		d.explain = func() (*ketoclient.Explanation, error) {
+			return c.ExplainOryAccessControlPolicy(flavor, d.request)
+		}
+	case *ketoclient.FlavorClient:
+		flavor := o.or(c.Flavor())
+		d.check(c.Client(), flavor)
+		d.explain = func() (*ketoclient.Explanation, error) {
+			return c.Client().ExplainOryAccessControlPolicy(flavor, d.request)
+		}
+	case ketoclient.Checker:
+		d.check(c, o.or(ketoclient.Exact))
+	default:
+		d.err = fmt.Errorf("ketotest: cannot check the requests with %T", checker)
+	}
+	return d
+}
+
+func (d *decision) check(checker ketoclient.Checker, flavor ketoclient.Flavor) {
+	response, err := checker.AllowedOryAccessControlPolicy(flavor, d.request)
+	if err != nil {
+		d.err = err
+		return
+	}
+	d.allowed = response.Allowed
+}
+
+// message describes the failure of the assertion expecting the request to be
+// allowed, or denied, and lists the policies matching it.
+func (d *decision) message(allowed bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Expected %q to be %s to %q %q, but it is %s",
+		d.request.Subject, outcome(allowed), d.request.Action, d.request.Resource, outcome(d.allowed))
+	if d.explain == nil {
+		return b.String()
+	}
+
+	explanation, err := d.explain()
+	if err != nil {
+		fmt.Fprintf(&b, "\nThe matching policies are unknown: %s", err)
+		return b.String()
+	}
+	if len(explanation.Roles) > 0 {
+		fmt.Fprintf(&b, "\nRoles: %s", strings.Join(explanation.Roles, ", "))
+	}
+	if len(explanation.Matched) == 0 {
+		b.WriteString("\nNo policy matches the request, so it is denied by default")
+		return b.String()
+	}
+	b.WriteString("\nMatching policies:")
+	for _, evaluation := range explanation.Matched {
+		policy := evaluation.Policy
+		fmt.Fprintf(&b, "\n  - %s (%s): subjects %q, actions %q, resources %q",
+			policy.ID, policy.Effect, policy.Subjects, policy.Actions, policy.Resources)
+		if explanation.DecidedBy != nil && explanation.DecidedBy.ID == policy.ID {
+			b.WriteString(", decisive")
+		}
+	}
+	return b.String()
+}
+
+func outcome(allowed bool) string {
+	if allowed {
+		return "allowed"
+	}
+	return "denied"
+}
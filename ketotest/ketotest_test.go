@@ -0,0 +1,11 @@
+package ketotest_test
+
+import (
+	"testing"
+
+	"github.com/lab259/ory-keto-client/ginkgotest"
+)
+
+func TestPackage(t *testing.T) {
+	ginkgotest.Init("Keto Test Helpers Test Suite", t)
+}
@@ -0,0 +1,61 @@
+package ketotest
+
+import (
+	"github.com/onsi/gomega/types"
+)
+
+// BeAllowedTo succeeds when the checker allows the subject to perform the
+// action on the resource:
+//
+//	Expect(client).To(ketotest.BeAllowedTo("alice", "read", "blog:1"))
+//
+// The options, `InFlavor` for instance, apply to the check:
+//
+//	Expect(tenant).To(ketotest.BeAllowedTo("alice", "read", "blog:*", ketotest.InFlavor(ketoclient.Glob)))
+//
+// It errors when the checker fails to decide.
+func BeAllowedTo(subject, action, resource string, opts ...Option) types.GomegaMatcher {
+	return &decisionMatcher{
+		subject:  subject,
+		action:   action,
+		resource: resource,
+		allowed:  true,
+		options:  newOptions(opts),
+	}
+}
+
+// BeDeniedTo succeeds when the checker denies the subject to perform the
+// action on the resource. See `BeAllowedTo`.
+func BeDeniedTo(subject, action, resource string, opts ...Option) types.GomegaMatcher {
+	return &decisionMatcher{
+		subject:  subject,
+		action:   action,
+		resource: resource,
+		options:  newOptions(opts),
+	}
+}
+
+type decisionMatcher struct {
+	subject  string
+	action   string
+	resource string
+	allowed  bool
+	options  options
+	decision *decision
+}
+
+func (m *decisionMatcher) Match(actual interface{}) (bool, error) {
+	m.decision = decide(actual, m.subject, m.action, m.resource, m.options)
+	if m.decision.err != nil {
+		return false, m.decision.err
+	}
+	return m.decision.allowed == m.allowed, nil
+}
+
+func (m *decisionMatcher) FailureMessage(actual interface{}) string {
+	return m.decision.message(m.allowed)
+}
+
+func (m *decisionMatcher) NegatedFailureMessage(actual interface{}) string {
+	return m.decision.message(!m.allowed)
+}
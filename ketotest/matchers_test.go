@@ -0,0 +1,151 @@
+package ketotest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+	"github.com/lab259/ory-keto-client/ketomock"
+	"github.com/lab259/ory-keto-client/ketotest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	policies = []ketoclient.ORYAccessControlPolicy{
+		{
+			ID:        "editors",
+			Subjects:  []string{"editor"},
+			Actions:   []string{"read", "write"},
+			Resources: []string{"blog"},
+			Effect:    ketoclient.Allow,
+		},
+		{
+			ID:        "banned",
+			Subjects:  []string{"mallory"},
+			Actions:   []string{"write"},
+			Resources: []string{"blog"},
+			Effect:    ketoclient.Deny,
+		},
+	}
+	roles = []ketoclient.ORYAccessControlRole{
+		{ID: "editor", Members: []string{"alice", "mallory"}},
+	}
+)
+
+// newServer serves the decisions of the evaluator and its policies and roles.
+func newServer(evaluator *ketoclient.Evaluator) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/allowed"):
+			var request ketoclient.AllowedORYAccessControlPolicyRequest
+			Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+			allowed, err := evaluator.Allowed(&request)
+			Expect(err).ToNot(HaveOccurred())
+			if !allowed {
+				w.WriteHeader(http.StatusForbidden)
+			}
+			Expect(json.NewEncoder(w).Encode(map[string]bool{"allowed": allowed})).To(Succeed())
+		case strings.HasSuffix(r.URL.Path, "/policies"):
+			Expect(json.NewEncoder(w).Encode(policies)).To(Succeed())
+		case strings.HasSuffix(r.URL.Path, "/roles"):
+			Expect(json.NewEncoder(w).Encode(roles)).To(Succeed())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+var _ = Describe("Matchers", func() {
+	evaluator := ketoclient.NewEvaluator(ketoclient.Exact, policies, roles)
+
+	It("should match the decisions of an evaluator", func() {
+		Expect(evaluator).To(ketotest.BeAllowedTo("alice", "write", "blog"))
+		Expect(evaluator).To(ketotest.BeDeniedTo("mallory", "write", "blog"))
+		Expect(evaluator).ToNot(ketotest.BeAllowedTo("bob", "read", "blog"))
+		Expect(evaluator).ToNot(ketotest.BeDeniedTo("mallory", "read", "blog"))
+	})
+
+	It("should print the matching policies", func() {
+		matcher := ketotest.BeAllowedTo("mallory", "write", "blog")
+		Expect(matcher.Match(evaluator)).To(BeFalse())
+		Expect(matcher.FailureMessage(evaluator)).To(Equal(`Expected "mallory" to be allowed to "write" "blog", but it is denied
+Roles: editor
+Matching policies:
+  - editors (allow): subjects ["editor"], actions ["read" "write"], resources ["blog"]
+  - banned (deny): subjects ["mallory"], actions ["write"], resources ["blog"], decisive`))
+
+		matcher = ketotest.BeDeniedTo("alice", "read", "blog")
+		Expect(matcher.Match(evaluator)).To(BeFalse())
+		Expect(matcher.FailureMessage(evaluator)).To(ContainSubstring("editors (allow)"))
+
+		matcher = ketotest.BeAllowedTo("bob", "read", "blog")
+		Expect(matcher.Match(evaluator)).To(BeFalse())
+		Expect(matcher.FailureMessage(evaluator)).To(HaveSuffix("No policy matches the request, so it is denied by default"))
+		Expect(matcher.NegatedFailureMessage(evaluator)).To(HavePrefix(`Expected "bob" to be denied to "read" "blog", but it is denied`))
+	})
+
+	It("should match the decisions of a client", func() {
+		server := newServer(evaluator)
+		defer server.Close()
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		client := ketoclient.New(ketoclient.WithURL(u), ketoclient.WithBreakerPrefix("ketotest-client"))
+
+		Expect(client).To(ketotest.BeAllowedTo("alice", "read", "blog"))
+		Expect(client.Flavor(ketoclient.Exact)).To(ketotest.BeDeniedTo("mallory", "write", "blog"))
+
+		matcher := ketotest.BeAllowedTo("mallory", "write", "blog")
+		Expect(matcher.Match(client)).To(BeFalse())
+		Expect(matcher.FailureMessage(client)).To(ContainSubstring("banned (deny)"))
+	})
+
+	It("should match the decisions of any checker", func() {
+		mock := &ketomock.Client{}
+		Expect(mock).To(ketotest.BeDeniedTo("alice", "read", "blog"))
+
+		matcher := ketotest.BeAllowedTo("alice", "read", "blog")
+		Expect(matcher.Match(mock)).To(BeFalse())
+		Expect(matcher.FailureMessage(mock)).To(Equal(`Expected "alice" to be allowed to "read" "blog", but it is denied`))
+		Expect(mock.CallsTo("AllowedOryAccessControlPolicy")[0].Flavor).To(Equal(ketoclient.Exact))
+	})
+
+	It("should check in the given flavor", func() {
+		mock := &ketomock.Client{}
+		Expect(mock).To(ketotest.BeDeniedTo("alice", "read", "blog", ketotest.InFlavor(ketoclient.Glob)))
+		Expect(mock.CallsTo("AllowedOryAccessControlPolicy")[0].Flavor).To(Equal(ketoclient.Glob))
+
+		var path string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			Expect(json.NewEncoder(w).Encode(map[string]bool{"allowed": true})).To(Succeed())
+		}))
+		defer server.Close()
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		tenant, err := ketoclient.New(ketoclient.WithURL(u), ketoclient.WithBreakerPrefix("ketotest-tenant")).Tenant("acme")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tenant).To(ketotest.BeAllowedTo("alice", "read", "blog", ketotest.InFlavor(ketoclient.Regex)))
+		Expect(path).To(Equal("/engines/acp/ory/regex/allowed"))
+
+		_, err = ketotest.BeAllowedTo("alice", "read", "blog", ketotest.InFlavor(ketoclient.Glob)).Match(evaluator)
+		Expect(err).To(MatchError("ketotest: the evaluator decides in the exact flavor, not in the glob one"))
+	})
+
+	It("should fail when the decision cannot be made", func() {
+		_, err := ketotest.BeAllowedTo("alice", "read", "blog").Match("client")
+		Expect(err).To(MatchError("ketotest: cannot check the requests with string"))
+
+		mock := &ketomock.Client{
+			AllowedOryAccessControlPolicyFunc: func(ketoclient.Flavor, *ketoclient.AllowedORYAccessControlPolicyRequest, ...ketoclient.CallOption) (*ketoclient.AllowedORYAccessControlPolicyResponse, error) {
+				return nil, ketoclient.ErrNotFound
+			},
+		}
+		_, err = ketotest.BeDeniedTo("alice", "read", "blog").Match(mock)
+		Expect(err).To(Equal(ketoclient.ErrNotFound))
+	})
+})
@@ -0,0 +1,55 @@
+package ketoclient
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger records the requests made by the client. It is implemented by
+// `*slog.Logger`.
+type Logger interface {
+	// Enabled reports whether the logger records messages of the level.
+	Enabled(ctx context.Context, level slog.Level) bool
+
+	// Log records a message with alternating keys and values, as
+	// `slog.Logger.Log` does.
+	Log(ctx context.Context, level slog.Level, msg string, args ...any)
+}
+
+// LogLevels defines the levels of the messages logged by the client.
+type LogLevels struct {
+	// Request is the level of the requests sent to the server.
+	Request slog.Level
+
+	// Response is the level of the responses of the server, with their status
+	// and duration, or of the errors that prevented them.
+	Response slog.Level
+
+	// Failure is the level of the operations that failed, with the error
+	// returned to the caller.
+	Failure slog.Level
+}
+
+// DefaultLogLevels are the levels used when `WithLogLevels` is not given.
+var DefaultLogLevels = LogLevels{
+	Request:  slog.LevelDebug,
+	Response: slog.LevelDebug,
+	Failure:  slog.LevelError,
+}
+
+// log records a message of the operation with its attributes, which are
+// already redacted, and the redacted context of the check.
+func (c *call) log(level slog.Level, msg string, args ...any) {
+	logger := c.client.logger
+	if logger == nil || !logger.Enabled(c.ctx, level) {
+		return
+	}
+	attrs := make([]any, 0, 2*len(c.attrs)+len(args)+2)
+	for _, attr := range c.attrs {
+		attrs = append(attrs, string(attr.Key), attr.Value.AsInterface())
+	}
+	if c.checkContext != nil {
+		attrs = append(attrs, "keto.context", c.client.redactContext(c.checkContext))
+	}
+	logger.Log(c.ctx, level, msg, append(attrs, args...)...)
+}
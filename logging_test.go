@@ -0,0 +1,152 @@
+package ketoclient_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// logBuffer collects the records of a JSON `slog.Handler`.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) Records() []map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		record := make(map[string]interface{})
+		Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+		records = append(records, record)
+	}
+	return records
+}
+
+var _ = Describe("Logging", func() {
+	var (
+		keto *fakeKeto
+		logs *logBuffer
+	)
+
+	BeforeEach(func() {
+		keto = newFakeKeto()
+		keto.AddPolicies(ketoclient.Exact, ketoclient.ORYAccessControlPolicy{
+			ID:        "policy1",
+			Subjects:  []string{"user:snake-eyes"},
+			Actions:   []string{"delete"},
+			Resources: []string{"blog1:post:33"},
+			Effect:    ketoclient.Allow,
+		})
+		logs = &logBuffer{}
+	})
+
+	AfterEach(func() {
+		keto.Close()
+	})
+
+	logger := func(level slog.Level) *slog.Logger {
+		return slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: level}))
+	}
+
+	check := func(client *ketoclient.Client) {
+		_, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: "blog1:post:33",
+			Context:  map[string]interface{}{"remoteIP": "10.0.0.1", "tenant": "blog1"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("should log the requests and responses with redaction", func() {
+		check(keto.Client(ketoclient.WithLogger(logger(slog.LevelDebug))))
+
+		records := logs.Records()
+		Expect(records).To(HaveLen(2))
+		Expect(records[0]).To(HaveKeyWithValue("level", "DEBUG"))
+		Expect(records[0]).To(HaveKeyWithValue("msg", "keto request"))
+		Expect(records[0]).To(HaveKeyWithValue("keto.operation", "AllowedOryAccessControlPolicy"))
+		Expect(records[0]).To(HaveKeyWithValue("keto.flavor", "exact"))
+		Expect(records[0]).To(HaveKeyWithValue("keto.subject", "[redacted]"))
+		Expect(records[0]).To(HaveKeyWithValue("keto.resource", "blog1:post:33"))
+		Expect(records[0]).To(HaveKeyWithValue("keto.context", map[string]interface{}{"remoteIP": "[redacted]", "tenant": "[redacted]"}))
+		Expect(records[0]).To(HaveKeyWithValue("http.request.method", "POST"))
+		Expect(records[0]).To(HaveKeyWithValue("server.address", keto.URL().String()))
+
+		Expect(records[1]).To(HaveKeyWithValue("msg", "keto response"))
+		Expect(records[1]).To(HaveKeyWithValue("http.response.status_code", BeNumerically("==", 200)))
+		Expect(records[1]).To(HaveKey("duration"))
+		Expect(records[1]).ToNot(HaveKey("error"))
+	})
+
+	It("should apply the configured redaction", func() {
+		check(keto.Client(
+			ketoclient.WithLogger(logger(slog.LevelDebug)),
+			ketoclient.WithSubjectRedactor(ketoclient.RedactHash),
+			ketoclient.WithResourceRedactor(ketoclient.RedactAll),
+			ketoclient.WithContextRedactor(ketoclient.RedactContextKeys("remoteIP")),
+		))
+
+		records := logs.Records()
+		Expect(records).ToNot(BeEmpty())
+		Expect(records[0]).To(HaveKeyWithValue("keto.subject", ketoclient.RedactHash("user:snake-eyes")))
+		Expect(records[0]).To(HaveKeyWithValue("keto.resource", "[redacted]"))
+		Expect(records[0]).To(HaveKeyWithValue("keto.context", map[string]interface{}{"remoteIP": "[redacted]", "tenant": "blog1"}))
+	})
+
+	It("should log the failures at the error level", func() {
+		client := keto.Client(ketoclient.WithLogger(logger(slog.LevelInfo)))
+		_, err := client.GetOryAccessControlPolicy(ketoclient.Exact, "unknown")
+		Expect(err).To(HaveOccurred())
+
+		records := logs.Records()
+		Expect(records).To(HaveLen(1))
+		Expect(records[0]).To(HaveKeyWithValue("level", "ERROR"))
+		Expect(records[0]).To(HaveKeyWithValue("msg", "keto operation failed"))
+		Expect(records[0]).To(HaveKeyWithValue("keto.policy.id", "unknown"))
+		Expect(records[0]).To(HaveKeyWithValue("error", ketoclient.ErrNotFound.Error()))
+		Expect(records[0]).To(HaveKey("duration"))
+	})
+
+	It("should log at the configured levels", func() {
+		client := keto.Client(
+			ketoclient.WithLogger(logger(slog.LevelInfo)),
+			ketoclient.WithLogLevels(ketoclient.LogLevels{
+				Request:  slog.LevelDebug,
+				Response: slog.LevelInfo,
+				Failure:  slog.LevelWarn,
+			}),
+		)
+		_, err := client.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+		keto.Close()
+		_, err = client.HealthAlive()
+		Expect(err).To(HaveOccurred())
+
+		records := logs.Records()
+		Expect(records).To(HaveLen(3))
+		Expect(records[0]).To(HaveKeyWithValue("msg", "keto response"))
+		Expect(records[0]).To(HaveKeyWithValue("level", "INFO"))
+		Expect(records[1]).To(HaveKeyWithValue("msg", "keto response"))
+		Expect(records[1]).To(HaveKey("error"))
+		Expect(records[2]).To(HaveKeyWithValue("msg", "keto operation failed"))
+		Expect(records[2]).To(HaveKeyWithValue("level", "WARN"))
+	})
+})
@@ -0,0 +1,168 @@
+package ketoclient
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/lab259/errors/v2"
+)
+
+// ErrInvalidPattern is returned when a policy contains a subject, resource or
+// action that cannot be compiled for the given flavor.
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+// globDelimiter is the separator used by Keto when matching the glob flavor.
+// Single wildcards (`*` and `?`) never cross it.
+const globDelimiter = ':'
+
+// matcher reports if a value matches a policy pattern.
+type matcher func(value string) bool
+
+// compileMatcher compiles a subject, resource or action pattern using the
+// matching strategy of the given flavor.
+//
+// See Also https://www.ory.sh/docs/keto/engines/acp-ory#pattern-matching-strategies
+func compileMatcher(flavor Flavor, pattern string) (matcher, error) {
+	switch flavor {
+	case Exact:
+		return func(value string) bool {
+			return value == pattern
+		}, nil
+	case Glob:
+		re, err := compileGlob(pattern)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidPattern, errors.Message(pattern+": "+err.Error()))
+		}
+		return re.MatchString, nil
+	case Regex:
+		re, err := compileRegexTemplate(pattern)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidPattern, errors.Message(pattern+": "+err.Error()))
+		}
+		return re.MatchString, nil
+	default:
+		return nil, errors.Wrap(ErrInvalidPattern, errors.Message("unknown flavor "+string(flavor)))
+	}
+}
+
+// matchAny reports if any of the patterns matches the value.
+func matchAny(flavor Flavor, patterns []string, value string) (bool, error) {
+	for _, pattern := range patterns {
+		m, err := compileMatcher(flavor, pattern)
+		if err != nil {
+			return false, err
+		}
+		if m(value) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// literal reports whether the pattern only matches itself with the matching
+// strategy of the flavor.
+func literal(flavor Flavor, pattern string) bool {
+	switch flavor {
+	case Glob:
+		return !strings.ContainsAny(pattern, `*?[]{}\`)
+	case Regex:
+		return !strings.ContainsRune(pattern, '<')
+	}
+	return true
+}
+
+// compileGlob translates a glob pattern into an anchored regular expression.
+//
+// It supports `*` (any sequence without delimiters), `**` (any sequence),
+// `?` (any single character but a delimiter), character lists (`[abc]`,
+// `[!abc]`, `[a-z]`), alternatives (`{a,b}`) and `\` escaping.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	notDelimiter := "[^" + regexp.QuoteMeta(string(globDelimiter)) + "]"
+
+	var buf strings.Builder
+	buf.WriteString("^")
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '\\':
+			i++
+			if i >= len(pattern) {
+				return nil, errors.New("trailing escape")
+			}
+			buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				buf.WriteString(".*")
+			} else {
+				buf.WriteString(notDelimiter + "*")
+			}
+		case '?':
+			buf.WriteString(notDelimiter)
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, errors.New("unclosed character list")
+			}
+			list := pattern[i+1 : i+1+end]
+			i += end + 1
+			buf.WriteByte('[')
+			if strings.HasPrefix(list, "!") {
+				buf.WriteByte('^')
+				list = list[1:]
+			}
+			buf.WriteString(strings.Replace(list, `\`, `\\`, -1))
+			buf.WriteByte(']')
+		case '{':
+			depth++
+			buf.WriteString("(?:")
+		case '}':
+			if depth == 0 {
+				buf.WriteString(`\}`)
+				continue
+			}
+			depth--
+			buf.WriteString(")")
+		case ',':
+			if depth > 0 {
+				buf.WriteString("|")
+			} else {
+				buf.WriteString(",")
+			}
+		default:
+			buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	if depth != 0 {
+		return nil, errors.New("unclosed alternative")
+	}
+	buf.WriteString("$")
+	return regexp.Compile(buf.String())
+}
+
+// compileRegexTemplate compiles a regex flavored pattern. Only the parts
+// enclosed in `<` and `>` are regular expressions, everything else is matched
+// literally.
+func compileRegexTemplate(pattern string) (*regexp.Regexp, error) {
+	var buf strings.Builder
+	buf.WriteString("^")
+	for {
+		start := strings.IndexByte(pattern, '<')
+		if start < 0 {
+			buf.WriteString(regexp.QuoteMeta(pattern))
+			break
+		}
+		end := strings.IndexByte(pattern[start:], '>')
+		if end < 0 {
+			return nil, errors.New("unclosed delimiter")
+		}
+		buf.WriteString(regexp.QuoteMeta(pattern[:start]))
+		buf.WriteString("(?:")
+		buf.WriteString(pattern[start+1 : start+end])
+		buf.WriteString(")")
+		pattern = pattern[start+end+1:]
+	}
+	buf.WriteString("$")
+	return regexp.Compile(buf.String())
+}
@@ -0,0 +1,45 @@
+package ketoclient
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("compileMatcher", func() {
+	table.DescribeTable("should match values according to the flavor",
+		func(flavor Flavor, pattern, value string, expected bool) {
+			m, err := compileMatcher(flavor, pattern)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(m(value)).To(Equal(expected))
+		},
+		table.Entry("exact equal", Exact, "blog1:post:33", "blog1:post:33", true),
+		table.Entry("exact is case sensitive", Exact, "blog1:post:33", "Blog1:post:33", false),
+		table.Entry("exact does not expand wildcards", Exact, "blog1:*", "blog1:post", false),
+		table.Entry("glob wildcard", Glob, "blog1:post:*", "blog1:post:33", true),
+		table.Entry("glob wildcard does not cross delimiters", Glob, "blog1:*", "blog1:post:33", false),
+		table.Entry("glob super wildcard", Glob, "blog1:**", "blog1:post:33", true),
+		table.Entry("glob single character", Glob, "blog1:post:3?", "blog1:post:34", true),
+		table.Entry("glob character list", Glob, "blog1:post:3[34]", "blog1:post:35", false),
+		table.Entry("glob negated character list", Glob, "blog1:post:3[!34]", "blog1:post:35", true),
+		table.Entry("glob alternatives", Glob, "blog1:{post,comment}:33", "blog1:comment:33", true),
+		table.Entry("glob escaping", Glob, `blog1:\*`, "blog1:*", true),
+		table.Entry("glob escaping does not expand", Glob, `blog1:\*`, "blog1:post", false),
+		table.Entry("regex template", Regex, "blog1:post:<[0-9]+>", "blog1:post:33", true),
+		table.Entry("regex template is anchored", Regex, "blog1:post:<[0-9]+>", "blog1:post:33a", false),
+		table.Entry("regex literal part", Regex, "blog1.post", "blog1:post", false),
+	)
+
+	It("should fail compiling an invalid pattern", func() {
+		_, err := compileMatcher(Regex, "blog1:<[0-9+>")
+		Expect(err).To(HaveOccurred())
+
+		_, err = compileMatcher(Glob, "blog1:{post")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail with an unknown flavor", func() {
+		_, err := compileMatcher(Flavor("unknown"), "blog1")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,113 @@
+package ketoclient
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsNamespace prefixes the names of the metrics of the client.
+const metricsNamespace = "keto_client"
+
+// metrics are the Prometheus metrics of a client. It implements
+// `prometheus.Collector`.
+type metrics struct {
+	requests  *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+	inFlight  *prometheus.GaugeVec
+	breaker   *prometheus.Desc
+	breakers  map[Category]*breaker
+}
+
+func newMetrics(breakers map[Category]*breaker, constLabels prometheus.Labels) *metrics {
+	labels := []string{"operation", "flavor", "status", "outcome"}
+	return &metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   metricsNamespace,
+			Name:        "requests_total",
+			Help:        "Number of operations made by the client.",
+			ConstLabels: constLabels,
+		}, labels),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   metricsNamespace,
+			Name:        "request_duration_seconds",
+			Help:        "Duration of the operations made by the client, retries included.",
+			Buckets:     prometheus.DefBuckets,
+			ConstLabels: constLabels,
+		}, labels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   metricsNamespace,
+			Name:        "requests_in_flight",
+			Help:        "Number of operations in progress.",
+			ConstLabels: constLabels,
+		}, []string{"operation"}),
+		breaker: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "breaker_open"),
+			"Whether the circuit breaker of a category of operations is open (1) or closed (0).",
+			[]string{"category"}, constLabels,
+		),
+		breakers: breakers,
+	}
+}
+
+func (m *metrics) begin(c *call) {
+	if m == nil {
+		return
+	}
+	m.inFlight.WithLabelValues(c.operation).Inc()
+}
+
+func (m *metrics) end(c *call, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.inFlight.WithLabelValues(c.operation).Dec()
+	labels := prometheus.Labels{
+		"operation": c.operation,
+		"flavor":    string(c.flavor),
+		"status":    c.statusLabel(),
+		"outcome":   c.outcome,
+	}
+	m.requests.With(labels).Inc()
+	m.durations.With(labels).Observe(duration.Seconds())
+}
+
+func (m *metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.durations.Describe(ch)
+	m.inFlight.Describe(ch)
+	ch <- m.breaker
+}
+
+func (m *metrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.durations.Collect(ch)
+	m.inFlight.Collect(ch)
+	for _, category := range categories {
+		var open float64
+		switch m.breakers[category].state() {
+		case BreakerOpen:
+			open = 1
+		case BreakerClosed:
+		default:
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(m.breaker, prometheus.GaugeValue, open, string(category))
+	}
+}
+
+// Collector returns the Prometheus collector of the metrics of the client,
+// to be registered by the caller:
+//
+//   - `keto_client_requests_total` counts the operations by operation,
+//     flavor, status code and outcome (`allowed`, `denied`, `ok` or `error`);
+//   - `keto_client_request_duration_seconds` observes their duration with the
+//     same labels;
+//   - `keto_client_requests_in_flight` gauges the operations in progress;
+//   - `keto_client_breaker_open` gauges the state of the circuit breakers.
+//
+// Clients registered in the same registry must be told apart by the labels
+// given by `WithMetricsLabels`.
+func (client *Client) Collector() prometheus.Collector {
+	return client.metrics
+}
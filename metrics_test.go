@@ -0,0 +1,146 @@
+package ketoclient_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Metrics", func() {
+	var keto *fakeKeto
+
+	BeforeEach(func() {
+		keto = newFakeKeto()
+		keto.AddPolicies(ketoclient.Exact, ketoclient.ORYAccessControlPolicy{
+			ID:        "policy1",
+			Subjects:  []string{"user:snake-eyes"},
+			Actions:   []string{"delete"},
+			Resources: []string{"blog1:post:33"},
+			Effect:    ketoclient.Allow,
+		})
+	})
+
+	AfterEach(func() {
+		keto.Close()
+	})
+
+	check := func(client *ketoclient.Client, resource string) {
+		_, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: resource,
+		})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("should count the operations by operation, flavor, status and outcome", func() {
+		client := keto.Client()
+		check(client, "blog1:post:33")
+		check(client, "blog1:post:33")
+		check(client, "blog1:post:34")
+		_, err := client.GetOryAccessControlPolicy(ketoclient.Exact, "policy1")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.GetOryAccessControlPolicy(ketoclient.Exact, "unknown")
+		Expect(err).To(HaveOccurred())
+
+		Expect(testutil.CollectAndCompare(client.Collector(), strings.NewReader(`
+# HELP keto_client_requests_total Number of operations made by the client.
+# TYPE keto_client_requests_total counter
+keto_client_requests_total{flavor="exact",operation="AllowedOryAccessControlPolicy",outcome="allowed",status="200"} 2
+keto_client_requests_total{flavor="exact",operation="AllowedOryAccessControlPolicy",outcome="denied",status="403"} 1
+keto_client_requests_total{flavor="exact",operation="GetOryAccessControlPolicy",outcome="error",status="404"} 1
+keto_client_requests_total{flavor="exact",operation="GetOryAccessControlPolicy",outcome="ok",status="200"} 1
+`), "keto_client_requests_total")).To(Succeed())
+		Expect(testutil.CollectAndCount(client.Collector(), "keto_client_request_duration_seconds")).To(Equal(4))
+	})
+
+	It("should label the operations that got no response", func() {
+		client := keto.Client()
+		keto.Close()
+		_, err := client.HealthAlive()
+		Expect(err).To(HaveOccurred())
+
+		Expect(testutil.CollectAndCompare(client.Collector(), strings.NewReader(`
+# HELP keto_client_requests_total Number of operations made by the client.
+# TYPE keto_client_requests_total counter
+keto_client_requests_total{flavor="",operation="HealthAlive",outcome="error",status=""} 1
+`), "keto_client_requests_total")).To(Succeed())
+	})
+
+	It("should gauge the operations in flight", func() {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		}))
+		defer server.Close()
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix())
+
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			_, err := client.HealthReadness()
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		inFlight := func(value string) func() error {
+			return func() error {
+				return testutil.CollectAndCompare(client.Collector(), strings.NewReader(`
+# HELP keto_client_requests_in_flight Number of operations in progress.
+# TYPE keto_client_requests_in_flight gauge
+keto_client_requests_in_flight{operation="HealthReadness"} `+value+`
+`), "keto_client_requests_in_flight")
+			}
+		}
+		Eventually(inFlight("1")).Should(Succeed())
+		close(release)
+		<-done
+		Expect(inFlight("0")()).To(Succeed())
+	})
+
+	It("should gauge the state of the circuit breakers", func() {
+		client := keto.Client(ketoclient.WithBreaker(ketoclient.CategoryWrite, ketoclient.BreakerSettings{
+			RequestVolumeThreshold: 1,
+			ErrorPercentThreshold:  1,
+			SleepWindow:            time.Minute,
+		}))
+		keto.Close()
+		for i := 0; i < 3; i++ {
+			Expect(client.DeleteOryAccessControlPolicy(ketoclient.Exact, "policy1")).ToNot(Succeed())
+		}
+
+		Expect(testutil.CollectAndCompare(client.Collector(), strings.NewReader(`
+# HELP keto_client_breaker_open Whether the circuit breaker of a category of operations is open (1) or closed (0).
+# TYPE keto_client_breaker_open gauge
+keto_client_breaker_open{category="check"} 0
+keto_client_breaker_open{category="health"} 0
+keto_client_breaker_open{category="read"} 0
+keto_client_breaker_open{category="write"} 1
+`), "keto_client_breaker_open")).To(Succeed())
+	})
+
+	It("should register several clients told apart by their labels", func() {
+		registry := prometheus.NewPedanticRegistry()
+		a := keto.Client(ketoclient.WithMetricsLabels(prometheus.Labels{"client": "a"}))
+		b := keto.Client(ketoclient.WithMetricsLabels(prometheus.Labels{"client": "b"}))
+		Expect(registry.Register(a.Collector())).To(Succeed())
+		Expect(registry.Register(b.Collector())).To(Succeed())
+
+		_, err := a.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = b.HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.GatherAndCount(registry, "keto_client_requests_total")).To(Equal(2))
+	})
+})
@@ -71,6 +71,15 @@ type AllowedORYAccessControlPolicyRequest struct {
 
 type AllowedORYAccessControlPolicyResponse struct {
 	Allowed bool `json:"allowed"`
+
+	// Stale is set when the decision was not given by the server, but
+	// recovered from the last known decision for the same request.
+	Stale bool `json:"-"`
+
+	// Defaulted is set when the server could not be reached and there was no
+	// recent decision for the same request, so the configured default was
+	// applied.
+	Defaulted bool `json:"-"`
 }
 
 /**
@@ -0,0 +1,335 @@
+package ketoclient
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// listPageSize is the page size used when the client needs to walk through
+// all the policies or roles of a flavor.
+const listPageSize = 100
+
+// Permission is a resource/action pair granted or denied to a subject. When
+// more than one policy produces the same pair, they are grouped together.
+type Permission struct {
+	Effect      Effect   `json:"effect"`
+	Resource    string   `json:"resource"`
+	Action      string   `json:"action"`
+	Conditional bool     `json:"conditional"`
+	Policies    []string `json:"policies"`
+
+	// OverriddenBy lists the deny policies that cancel this permission, or
+	// part of it. It is only filled for the permissions in
+	// `EffectivePermissions.Overridden` and `PartiallyDenied`.
+	OverriddenBy []string `json:"overridden_by,omitempty"`
+}
+
+// EffectivePermissions is the result of resolving everything a subject can,
+// or cannot, do.
+//
+// Deny-overrides is already applied: an allowed permission that is
+// unconditionally denied by another policy is moved from `Allowed` to
+// `Overridden`. It is only the case when the deny patterns cover the allow
+// ones: they are equal, or the allow is a literal value the deny matches.
+// An allowed permission whose patterns only overlap with a deny, `blog:*`
+// and `blog:?` for instance, stays in `Allowed` and is also listed in
+// `PartiallyDenied`.
+type EffectivePermissions struct {
+	Subject         string                   `json:"subject"`
+	Flavor          Flavor                   `json:"flavor"`
+	Roles           []string                 `json:"roles"`
+	Policies        []ORYAccessControlPolicy `json:"policies"`
+	Allowed         []Permission             `json:"allowed"`
+	Denied          []Permission             `json:"denied"`
+	Overridden      []Permission             `json:"overridden"`
+	PartiallyDenied []Permission             `json:"partially_denied"`
+}
+
+// EffectivePermissions resolves the roles of the subject and returns every
+// policy that applies to it, grouped by effect and resource/action.
+//
+// The subject is matched against the policies using the matching strategy of
+// the flavor, exactly like the Keto server does.
+func (client *Client) EffectivePermissions(flavor Flavor, subject string) (*EffectivePermissions, error) {
+	policies, err := client.listAllOryAccessControlPolicies(flavor)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := client.listAllOryAccessControlRoles(flavor)
+	if err != nil {
+		return nil, err
+	}
+	return resolveEffectivePermissions(flavor, subject, policies, roles)
+}
+
+func resolveEffectivePermissions(flavor Flavor, subject string, policies []ORYAccessControlPolicy, roles []ORYAccessControlRole) (*EffectivePermissions, error) {
+	r := &EffectivePermissions{
+		Subject:    subject,
+		Flavor:     flavor,
+		Roles:      subjectRoles(subject, roles),
+		Policies:   make([]ORYAccessControlPolicy, 0),
+		Allowed:    make([]Permission, 0),
+		Denied:     make([]Permission, 0),
+		Overridden: make([]Permission, 0),
+
+		PartiallyDenied: make([]Permission, 0),
+	}
+
+	subjects := append([]string{subject}, r.Roles...)
+	allowed := make(map[permissionKey]*Permission)
+	denied := make(map[permissionKey]*Permission)
+	for _, policy := range policies {
+		applies := false
+		for _, s := range subjects {
+			ok, err := matchAny(flavor, policy.Subjects, s)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				applies = true
+				break
+			}
+		}
+		if !applies {
+			continue
+		}
+		r.Policies = append(r.Policies, policy)
+
+		group := allowed
+		if policy.Effect == Deny {
+			group = denied
+		}
+		conditional := hasConditions(policy.Conditions)
+		for _, resource := range policy.Resources {
+			for _, action := range policy.Actions {
+				key := permissionKey{resource, action, conditional}
+				p, ok := group[key]
+				if !ok {
+					p = &Permission{
+						Effect:      policy.Effect,
+						Resource:    resource,
+						Action:      action,
+						Conditional: conditional,
+					}
+					group[key] = p
+				}
+				p.Policies = append(p.Policies, policy.ID)
+			}
+		}
+	}
+	for _, p := range denied {
+		r.Denied = append(r.Denied, *p)
+	}
+	sortPermissions(r.Denied)
+
+	for _, p := range allowed {
+		overriddenBy, partiallyDeniedBy, err := overridingPolicies(flavor, p, r.Denied)
+		if err != nil {
+			return nil, err
+		}
+		if len(overriddenBy) > 0 {
+			p.OverriddenBy = overriddenBy
+			r.Overridden = append(r.Overridden, *p)
+			continue
+		}
+		r.Allowed = append(r.Allowed, *p)
+		if len(partiallyDeniedBy) > 0 {
+			partial := *p
+			partial.OverriddenBy = partiallyDeniedBy
+			r.PartiallyDenied = append(r.PartiallyDenied, partial)
+		}
+	}
+	sortPermissions(r.Allowed)
+	sortPermissions(r.Overridden)
+	sortPermissions(r.PartiallyDenied)
+
+	return r, nil
+}
+
+// WriteJSON exports the report as JSON.
+func (p *EffectivePermissions) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// WriteCSV exports the permissions of the report as CSV, one permission per
+// line. Policy IDs are separated by `;`. The partially denied permissions are
+// not repeated, as they are already exported as allowed.
+func (p *EffectivePermissions) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"subject", "effect", "resource", "action", "conditional", "policies", "overridden_by"})
+	if err != nil {
+		return err
+	}
+	for _, group := range [][]Permission{p.Allowed, p.Denied, p.Overridden} {
+		for _, permission := range group {
+			err := cw.Write([]string{
+				p.Subject,
+				string(permission.Effect),
+				permission.Resource,
+				permission.Action,
+				strconv.FormatBool(permission.Conditional),
+				strings.Join(permission.Policies, ";"),
+				strings.Join(permission.OverriddenBy, ";"),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type permissionKey struct {
+	resource    string
+	action      string
+	conditional bool
+}
+
+// subjectRoles returns the IDs of the roles that have the subject as member.
+func subjectRoles(subject string, roles []ORYAccessControlRole) []string {
+	r := make([]string, 0)
+	for _, role := range roles {
+		for _, member := range role.Members {
+			if member == subject {
+				r = append(r, role.ID)
+				break
+			}
+		}
+	}
+	return r
+}
+
+// overridingPolicies returns the IDs of the unconditional deny policies that
+// cover the allowed permission, and the IDs of the ones that only cover part
+// of it.
+func overridingPolicies(flavor Flavor, allowed *Permission, denied []Permission) (overriddenBy, partiallyDeniedBy []string, err error) {
+	for _, deny := range denied {
+		if deny.Conditional {
+			continue
+		}
+		resource, err := coverage(flavor, deny.Resource, allowed.Resource)
+		if err != nil {
+			return nil, nil, err
+		}
+		action, err := coverage(flavor, deny.Action, allowed.Action)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case resource == covered && action == covered:
+			overriddenBy = append(overriddenBy, deny.Policies...)
+		case resource != disjoint && action != disjoint:
+			partiallyDeniedBy = append(partiallyDeniedBy, deny.Policies...)
+		}
+	}
+	return overriddenBy, partiallyDeniedBy, nil
+}
+
+// overlap is how much of the values matched by an allow pattern a deny
+// pattern matches too.
+type overlap int
+
+const (
+	disjoint overlap = iota
+	partial
+	covered
+)
+
+// coverage tells how much of the allow pattern the deny pattern covers. The
+// deny covers the allow when both are equal, or when the allow is a literal
+// value the deny matches. They partially overlap when one of them matches
+// the other as if it were a value. Other overlaps of two wildcard patterns
+// are not detected.
+func coverage(flavor Flavor, deny, allow string) (overlap, error) {
+	if deny == allow {
+		return covered, nil
+	}
+	ok, err := matchAny(flavor, []string{deny}, allow)
+	if err != nil {
+		return disjoint, err
+	}
+	if ok && literal(flavor, allow) {
+		return covered, nil
+	}
+	if !ok {
+		ok, err = matchAny(flavor, []string{allow}, deny)
+		if err != nil {
+			return disjoint, err
+		}
+	}
+	if ok {
+		return partial, nil
+	}
+	return disjoint, nil
+}
+
+func hasConditions(conditions interface{}) bool {
+	if conditions == nil {
+		return false
+	}
+	v := reflect.ValueOf(conditions)
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		return v.Len() > 0
+	}
+	return true
+}
+
+func sortPermissions(permissions []Permission) {
+	sort.Slice(permissions, func(i, j int) bool {
+		a, b := permissions[i], permissions[j]
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Action != b.Action {
+			return a.Action < b.Action
+		}
+		return !a.Conditional && b.Conditional
+	})
+}
+
+// listAllOryAccessControlPolicies walks through all pages of policies of the
+// flavor.
+func (client *Client) listAllOryAccessControlPolicies(flavor Flavor, opts ...CallOption) ([]ORYAccessControlPolicy, error) {
+	policies := make([]ORYAccessControlPolicy, 0, listPageSize)
+	for offset := int64(0); ; offset += listPageSize {
+		response, err := client.ListOryAccessControlPolicy(flavor, &ListORYAccessPolicyRequest{
+			Limit:  listPageSize,
+			Offset: offset,
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, response.Policies...)
+		if len(response.Policies) < listPageSize {
+			return policies, nil
+		}
+	}
+}
+
+// listAllOryAccessControlRoles walks through all pages of roles of the
+// flavor.
+func (client *Client) listAllOryAccessControlRoles(flavor Flavor, opts ...CallOption) ([]ORYAccessControlRole, error) {
+	roles := make([]ORYAccessControlRole, 0, listPageSize)
+	for offset := int64(0); ; offset += listPageSize {
+		response, err := client.ListOryAccessControlRole(flavor, &ListORYAccessRoleRequest{
+			Limit:  listPageSize,
+			Offset: offset,
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, response.Roles...)
+		if len(response.Roles) < listPageSize {
+			return roles, nil
+		}
+	}
+}
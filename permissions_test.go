@@ -0,0 +1,216 @@
+package ketoclient_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EffectivePermissions", func() {
+	var keto *fakeKeto
+
+	BeforeEach(func() {
+		keto = newFakeKeto()
+	})
+
+	AfterEach(func() {
+		keto.Close()
+	})
+
+	It("should resolve the policies of the subject and its roles", func() {
+		keto.AddRoles(ketoclient.Exact, ketoclient.ORYAccessControlRole{
+			ID:      "role:editors",
+			Members: []string{"user:snake-eyes", "user:scarlet"},
+		}, ketoclient.ORYAccessControlRole{
+			ID:      "role:admins",
+			Members: []string{"user:duke"},
+		})
+		keto.AddPolicies(ketoclient.Exact, ketoclient.ORYAccessControlPolicy{
+			ID:        "policy1",
+			Subjects:  []string{"user:snake-eyes"},
+			Resources: []string{"blog1:post:33"},
+			Actions:   []string{"delete"},
+			Effect:    ketoclient.Allow,
+		}, ketoclient.ORYAccessControlPolicy{
+			ID:        "policy2",
+			Subjects:  []string{"role:editors"},
+			Resources: []string{"blog1:post:33", "blog1:post:34"},
+			Actions:   []string{"edit"},
+			Effect:    ketoclient.Allow,
+		}, ketoclient.ORYAccessControlPolicy{
+			ID:        "policy3",
+			Subjects:  []string{"role:admins"},
+			Resources: []string{"blog1:post:35"},
+			Actions:   []string{"edit"},
+			Effect:    ketoclient.Allow,
+		})
+
+		report, err := keto.Client().EffectivePermissions(ketoclient.Exact, "user:snake-eyes")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Subject).To(Equal("user:snake-eyes"))
+		Expect(report.Roles).To(ConsistOf("role:editors"))
+		Expect(report.Policies).To(HaveLen(2))
+		Expect(report.Denied).To(BeEmpty())
+		Expect(report.Allowed).To(Equal([]ketoclient.Permission{
+			{Effect: ketoclient.Allow, Resource: "blog1:post:33", Action: "delete", Policies: []string{"policy1"}},
+			{Effect: ketoclient.Allow, Resource: "blog1:post:33", Action: "edit", Policies: []string{"policy2"}},
+			{Effect: ketoclient.Allow, Resource: "blog1:post:34", Action: "edit", Policies: []string{"policy2"}},
+		}))
+	})
+
+	It("should apply deny-overrides", func() {
+		keto.AddPolicies(ketoclient.Glob, ketoclient.ORYAccessControlPolicy{
+			ID:        "allow-posts",
+			Subjects:  []string{"user:*"},
+			Resources: []string{"blog1:post:33", "blog1:post:34"},
+			Actions:   []string{"delete"},
+			Effect:    ketoclient.Allow,
+		}, ketoclient.ORYAccessControlPolicy{
+			ID:        "deny-post-34",
+			Subjects:  []string{"user:snake-eyes"},
+			Resources: []string{"blog1:post:3{4,5}"},
+			Actions:   []string{"*"},
+			Effect:    ketoclient.Deny,
+		}, ketoclient.ORYAccessControlPolicy{
+			ID:         "deny-post-33-conditionally",
+			Subjects:   []string{"user:snake-eyes"},
+			Resources:  []string{"blog1:post:33"},
+			Actions:    []string{"delete"},
+			Effect:     ketoclient.Deny,
+			Conditions: map[string]interface{}{"ip": map[string]interface{}{"type": "CIDRCondition"}},
+		})
+
+		report, err := keto.Client().EffectivePermissions(ketoclient.Glob, "user:snake-eyes")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Allowed).To(Equal([]ketoclient.Permission{
+			{Effect: ketoclient.Allow, Resource: "blog1:post:33", Action: "delete", Policies: []string{"allow-posts"}},
+		}))
+		Expect(report.Denied).To(Equal([]ketoclient.Permission{
+			{Effect: ketoclient.Deny, Resource: "blog1:post:33", Action: "delete", Conditional: true, Policies: []string{"deny-post-33-conditionally"}},
+			{Effect: ketoclient.Deny, Resource: "blog1:post:3{4,5}", Action: "*", Policies: []string{"deny-post-34"}},
+		}))
+		Expect(report.Overridden).To(Equal([]ketoclient.Permission{
+			{Effect: ketoclient.Allow, Resource: "blog1:post:34", Action: "delete", Policies: []string{"allow-posts"}, OverriddenBy: []string{"deny-post-34"}},
+		}))
+	})
+
+	It("should only override the allowed permissions the denies cover", func() {
+		policies := []ketoclient.ORYAccessControlPolicy{{
+			ID:        "allow-blog",
+			Subjects:  []string{"user:snake-eyes"},
+			Resources: []string{"blog:*", "blog:**"},
+			Actions:   []string{"read", "edit"},
+			Effect:    ketoclient.Allow,
+		}, {
+			ID:        "deny-short-posts",
+			Subjects:  []string{"user:snake-eyes"},
+			Resources: []string{"blog:?"},
+			Actions:   []string{"read"},
+			Effect:    ketoclient.Deny,
+		}, {
+			ID:        "deny-post1",
+			Subjects:  []string{"user:snake-eyes"},
+			Resources: []string{"blog:post1"},
+			Actions:   []string{"edit"},
+			Effect:    ketoclient.Deny,
+		}, {
+			ID:        "deny-everything-edit",
+			Subjects:  []string{"user:snake-eyes"},
+			Resources: []string{"blog:**"},
+			Actions:   []string{"edit"},
+			Effect:    ketoclient.Deny,
+		}}
+		keto.AddPolicies(ketoclient.Glob, policies...)
+
+		report, err := keto.Client().EffectivePermissions(ketoclient.Glob, "user:snake-eyes")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Allowed).To(Equal([]ketoclient.Permission{
+			{Effect: ketoclient.Allow, Resource: "blog:*", Action: "edit", Policies: []string{"allow-blog"}},
+			{Effect: ketoclient.Allow, Resource: "blog:*", Action: "read", Policies: []string{"allow-blog"}},
+			{Effect: ketoclient.Allow, Resource: "blog:**", Action: "read", Policies: []string{"allow-blog"}},
+		}))
+		Expect(report.Overridden).To(Equal([]ketoclient.Permission{
+			{Effect: ketoclient.Allow, Resource: "blog:**", Action: "edit", Policies: []string{"allow-blog"}, OverriddenBy: []string{"deny-everything-edit"}},
+		}))
+		Expect(report.PartiallyDenied).To(Equal([]ketoclient.Permission{
+			{Effect: ketoclient.Allow, Resource: "blog:*", Action: "edit", Policies: []string{"allow-blog"}, OverriddenBy: []string{"deny-everything-edit", "deny-post1"}},
+			{Effect: ketoclient.Allow, Resource: "blog:*", Action: "read", Policies: []string{"allow-blog"}, OverriddenBy: []string{"deny-short-posts"}},
+			{Effect: ketoclient.Allow, Resource: "blog:**", Action: "read", Policies: []string{"allow-blog"}, OverriddenBy: []string{"deny-short-posts"}},
+		}))
+
+		evaluator := ketoclient.NewEvaluator(ketoclient.Glob, policies, nil)
+		allowed, err := evaluator.Allowed(&ketoclient.AllowedORYAccessControlPolicyRequest{Subject: "user:snake-eyes", Action: "read", Resource: "blog:post42"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowed).To(BeTrue())
+	})
+
+	It("should walk through all pages of policies", func() {
+		for i := 0; i < 250; i++ {
+			keto.AddPolicies(ketoclient.Exact, ketoclient.ORYAccessControlPolicy{
+				ID:        fmt.Sprintf("policy%d", i),
+				Subjects:  []string{"user:snake-eyes"},
+				Resources: []string{fmt.Sprintf("blog1:post:%d", i)},
+				Actions:   []string{"read"},
+				Effect:    ketoclient.Allow,
+			})
+		}
+
+		report, err := keto.Client().EffectivePermissions(ketoclient.Exact, "user:snake-eyes")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Policies).To(HaveLen(250))
+		Expect(report.Allowed).To(HaveLen(250))
+	})
+
+	Describe("export", func() {
+		var report *ketoclient.EffectivePermissions
+
+		BeforeEach(func() {
+			keto.AddPolicies(ketoclient.Exact, ketoclient.ORYAccessControlPolicy{
+				ID:        "policy1",
+				Subjects:  []string{"user:snake-eyes"},
+				Resources: []string{"blog1:post:33"},
+				Actions:   []string{"delete"},
+				Effect:    ketoclient.Allow,
+			}, ketoclient.ORYAccessControlPolicy{
+				ID:        "policy2",
+				Subjects:  []string{"user:snake-eyes"},
+				Resources: []string{"blog1:post:33"},
+				Actions:   []string{"delete"},
+				Effect:    ketoclient.Deny,
+			})
+
+			var err error
+			report, err = keto.Client().EffectivePermissions(ketoclient.Exact, "user:snake-eyes")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should export the report as JSON", func() {
+			buf := bytes.NewBuffer(nil)
+			Expect(report.WriteJSON(buf)).To(Succeed())
+
+			var decoded ketoclient.EffectivePermissions
+			Expect(json.Unmarshal(buf.Bytes(), &decoded)).To(Succeed())
+			Expect(decoded.Subject).To(Equal("user:snake-eyes"))
+			Expect(decoded.Denied).To(Equal(report.Denied))
+			Expect(decoded.Overridden).To(Equal(report.Overridden))
+		})
+
+		It("should export the report as CSV", func() {
+			buf := bytes.NewBuffer(nil)
+			Expect(report.WriteCSV(buf)).To(Succeed())
+
+			records, err := csv.NewReader(buf).ReadAll()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(records).To(Equal([][]string{
+				{"subject", "effect", "resource", "action", "conditional", "policies", "overridden_by"},
+				{"user:snake-eyes", "deny", "blog1:post:33", "delete", "false", "policy2", ""},
+				{"user:snake-eyes", "allow", "blog1:post:33", "delete", "false", "policy1", "policy2"},
+			}))
+		})
+	})
+})
@@ -0,0 +1,138 @@
+package ketoclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	hystrixgo "github.com/afex/hystrix-go/hystrix"
+	"github.com/lab259/errors/v2"
+)
+
+// ReadinessStage is the step of `WaitUntilReady` that failed.
+type ReadinessStage string
+
+const (
+	StageAlive   ReadinessStage = "alive"
+	StageReady   ReadinessStage = "ready"
+	StageVersion ReadinessStage = "version"
+)
+
+// ReadinessError is returned by `WaitUntilReady` when the server did not
+// become ready. It describes the last failure.
+type ReadinessError struct {
+	Attempts int
+	Elapsed  time.Duration
+	Stage    ReadinessStage
+	Err      error
+}
+
+func (err *ReadinessError) Error() string {
+	return fmt.Sprintf("keto server not ready after %d attempts (%s): %s check failed: %s", err.Attempts, err.Elapsed, err.Stage, err.Err)
+}
+
+// Unwrap returns the last failure.
+func (err *ReadinessError) Unwrap() error {
+	return err.Err
+}
+
+// Backoff configures the delays between the attempts of `WaitUntilReady`.
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// DefaultBackoff is the backoff used by `WaitUntilReady` when none is given.
+var DefaultBackoff = Backoff{
+	Initial:    100 * time.Millisecond,
+	Max:        5 * time.Second,
+	Multiplier: 2,
+}
+
+// orDefaults returns the backoff with the zero, or negative, fields replaced
+// by the ones of the defaults. A multiplier below 1 is replaced as well, so
+// the delays never shrink down to a tight loop.
+func (b Backoff) orDefaults(defaults Backoff) Backoff {
+	if b.Initial <= 0 {
+		b.Initial = defaults.Initial
+	}
+	if b.Max <= 0 {
+		b.Max = defaults.Max
+	}
+	if b.Multiplier < 1 {
+		b.Multiplier = defaults.Multiplier
+	}
+	return b
+}
+
+// next returns the delay that follows the given one.
+func (b Backoff) next(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return b.Initial
+	}
+	delay = time.Duration(float64(delay) * b.Multiplier)
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// WaitUntilReady polls `HealthAlive`, `HealthReadness` and `Version` until
+// the server is ready and compatible with this client, waiting between the
+// attempts according to the backoff. Its zero fields are taken from
+// `DefaultBackoff`.
+//
+// It gives up when the context is done, or right away if the server version
+// is incompatible, returning a `*ReadinessError`.
+func (client *Client) WaitUntilReady(ctx context.Context, backoff ...Backoff) error {
+	b := DefaultBackoff
+	if len(backoff) > 0 {
+		b = backoff[0].orDefaults(DefaultBackoff)
+	}
+
+	c := client.WithContext(ctx)
+	start := time.Now()
+	r := &ReadinessError{}
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		stage, err := c.checkReadiness()
+		if err == nil {
+			return nil
+		}
+		r.Attempts = attempt
+		r.Elapsed = time.Since(start)
+		// Polling a failing server opens the health circuit, and the deadline
+		// may interrupt the last attempt. Report the previous failure instead.
+		if r.Err == nil || (ctx.Err() == nil && !errors.Is(err, hystrixgo.ErrCircuitOpen)) {
+			r.Stage = stage
+			r.Err = err
+		}
+		if errors.Is(err, ErrServerIncompatible) {
+			return r
+		}
+
+		delay = b.next(delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			r.Elapsed = time.Since(start)
+			return r
+		}
+	}
+}
+
+func (client *Client) checkReadiness() (ReadinessStage, error) {
+	if _, err := client.HealthAlive(); err != nil {
+		return StageAlive, err
+	}
+	if _, err := client.HealthReadness(); err != nil {
+		return StageReady, err
+	}
+	if err := client.CheckVersion(); err != nil {
+		return StageVersion, err
+	}
+	return "", nil
+}
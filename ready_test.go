@@ -0,0 +1,95 @@
+package ketoclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/lab259/errors/v2"
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WaitUntilReady", func() {
+	var (
+		server  *httptest.Server
+		client  *ketoclient.Client
+		ready   int32
+		version atomic.Value
+		backoff = ketoclient.Backoff{Initial: time.Millisecond, Max: 5 * time.Millisecond, Multiplier: 2}
+	)
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&ready, 0)
+		version.Store("v0.3.3-sandbox")
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/health/alive":
+				writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+			case "/health/ready":
+				if atomic.AddInt32(&ready, 1) < 3 {
+					writeJSON(w, http.StatusServiceUnavailable, &ketoclient.ResponseError{Code: 503, Message: "database not ready"})
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+			case "/version":
+				writeJSON(w, http.StatusOK, map[string]string{"version": version.Load().(string)})
+			}
+		}))
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		client = ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should wait until the server is ready", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		Expect(client.WaitUntilReady(ctx, backoff)).To(Succeed())
+		Expect(atomic.LoadInt32(&ready)).To(Equal(int32(3)))
+	})
+
+	It("should fail right away with an incompatible server", func() {
+		version.Store("v0.2.0")
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		err := client.WaitUntilReady(ctx, backoff)
+		Expect(errors.Is(err, ketoclient.ErrServerIncompatible)).To(BeTrue())
+		readinessErr, ok := err.(*ketoclient.ReadinessError)
+		Expect(ok).To(BeTrue())
+		Expect(readinessErr.Stage).To(Equal(ketoclient.StageVersion))
+		Expect(readinessErr.Attempts).To(Equal(3))
+	})
+
+	It("should describe the last failure when the context is done", func() {
+		atomic.StoreInt32(&ready, -1000)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := client.WaitUntilReady(ctx, backoff)
+		readinessErr, ok := err.(*ketoclient.ReadinessError)
+		Expect(ok).To(BeTrue())
+		Expect(readinessErr.Stage).To(Equal(ketoclient.StageReady))
+		Expect(readinessErr.Attempts).To(BeNumerically(">", 1))
+		Expect(readinessErr.Error()).To(ContainSubstring("database not ready"))
+	})
+
+	It("should not poll in a tight loop with a zero backoff", func() {
+		atomic.StoreInt32(&ready, -1000)
+		ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+		defer cancel()
+
+		err := client.WaitUntilReady(ctx, ketoclient.Backoff{})
+		readinessErr, ok := err.(*ketoclient.ReadinessError)
+		Expect(ok).To(BeTrue())
+		Expect(readinessErr.Attempts).To(BeNumerically("<=", 3))
+	})
+})
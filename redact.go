@@ -0,0 +1,96 @@
+package ketoclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// redacted replaces the redacted values.
+const redacted = "[redacted]"
+
+// Redactor rewrites a sensitive value before it leaves the client in the
+// spans or in the logs.
+type Redactor func(value string) string
+
+// RedactAll replaces the whole value. It is the default redaction of the
+// subjects.
+func RedactAll(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redacted
+}
+
+// RedactNone keeps the value as it is. It is the default redaction of the
+// resources.
+func RedactNone(value string) string {
+	return value
+}
+
+// RedactHash replaces the value by a prefix of its SHA-256 hash, so equal
+// values can still be correlated.
+func RedactHash(value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// ContextRedactor rewrites the `Context` of an authorization check before it
+// is logged.
+type ContextRedactor func(context interface{}) interface{}
+
+// RedactContextValues keeps the keys of the context, replacing all its
+// values. It is the default redaction of the contexts.
+func RedactContextValues(context interface{}) interface{} {
+	var values map[string]interface{}
+	if err := normalizeJSON(context, &values); err != nil {
+		return redacted
+	}
+	for key := range values {
+		values[key] = redacted
+	}
+	return values
+}
+
+// RedactContextKeys returns a `ContextRedactor` that replaces the values of
+// the given keys of the context, keeping the others.
+func RedactContextKeys(keys ...string) ContextRedactor {
+	return func(context interface{}) interface{} {
+		var values map[string]interface{}
+		if err := normalizeJSON(context, &values); err != nil {
+			return redacted
+		}
+		for _, key := range keys {
+			if _, ok := values[key]; ok {
+				values[key] = redacted
+			}
+		}
+		return values
+	}
+}
+
+// redactSubject applies the redaction configured by `WithSubjectRedactor`.
+func (client *Client) redactSubject(subject string) string {
+	if client.subjectRedactor == nil {
+		return RedactAll(subject)
+	}
+	return client.subjectRedactor(subject)
+}
+
+// redactResource applies the redaction configured by `WithResourceRedactor`.
+func (client *Client) redactResource(resource string) string {
+	if client.resourceRedactor == nil {
+		return resource
+	}
+	return client.resourceRedactor(resource)
+}
+
+// redactContext applies the redaction configured by `WithContextRedactor`.
+func (client *Client) redactContext(context interface{}) interface{} {
+	if client.contextRedactor == nil {
+		return RedactContextValues(context)
+	}
+	return client.contextRedactor(context)
+}
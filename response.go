@@ -0,0 +1,48 @@
+package ketoclient
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	// maxDrainBytes bounds how much of an unread body is discarded so the
+	// connection can be reused. Larger bodies are closed, dropping the
+	// connection, instead of being read to the end.
+	maxDrainBytes = 64 << 10
+
+	// maxSnapshotBytes bounds how much of the body of the failed responses
+	// is read, and kept by `UnexpectedResponse`.
+	maxSnapshotBytes = 4 << 10
+)
+
+// drain discards what is left of the body of the response, up to
+// `maxDrainBytes`, and closes it.
+func drain(response *http.Response) {
+	if response == nil || response.Body == nil {
+		return
+	}
+	_, _ = io.CopyN(io.Discard, response.Body, maxDrainBytes)
+	_ = response.Body.Close()
+}
+
+// failure builds the error of a response the route does not accept: the
+// `ResponseError` sent by the server for the error statuses or, when there is
+// none, an `UnexpectedResponse` keeping a copy of the status, the headers and
+// the beginning of the body. The response is drained and closed by `invoke`.
+func failure(response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxSnapshotBytes))
+	if response.StatusCode >= http.StatusBadRequest {
+		r := &ResponseError{}
+		if json.Unmarshal(body, r) == nil && (r.Code != 0 || r.Message != "") {
+			return r
+		}
+	}
+	return &UnexpectedResponse{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Header:     response.Header.Clone(),
+		Body:       body,
+	}
+}
@@ -0,0 +1,141 @@
+package ketoclient_test
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Responses", func() {
+	var (
+		server      *httptest.Server
+		client      *ketoclient.Client
+		connections int64
+		handler     http.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		atomic.StoreInt64(&connections, 0)
+		keto := newFakeKeto()
+		keto.Close()
+		keto.AddPolicies(ketoclient.Exact, ketoclient.ORYAccessControlPolicy{
+			ID:        "policy1",
+			Subjects:  []string{"user:snake-eyes"},
+			Actions:   []string{"delete"},
+			Resources: []string{"blog1:post:33"},
+			Effect:    ketoclient.Allow,
+		})
+		keto.AddRoles(ketoclient.Exact, ketoclient.ORYAccessControlRole{
+			ID:      "role1",
+			Members: []string{"user:snake-eyes"},
+		})
+		handler = keto.serveHTTP
+
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(w, r)
+		}))
+		server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				atomic.AddInt64(&connections, 1)
+			}
+		}
+		server.Start()
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		client = ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should reuse the connection for every kind of response", func() {
+		// The first round opens the connection and the circuits, which keep
+		// their own goroutines.
+		var goroutines int
+		for i := 0; i < 50; i++ {
+			if i == 1 {
+				goroutines = runtime.NumGoroutine()
+			}
+
+			allowed, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+				Subject:  "user:snake-eyes",
+				Action:   "delete",
+				Resource: "blog1:post:33",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(allowed.Allowed).To(BeTrue())
+
+			denied, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+				Subject:  "user:baroness",
+				Action:   "delete",
+				Resource: "blog1:post:33",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(denied.Allowed).To(BeFalse())
+
+			_, err = client.GetOryAccessControlPolicy(ketoclient.Exact, "policy1")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = client.GetOryAccessControlPolicy(ketoclient.Exact, "unknown")
+			Expect(err).To(Equal(ketoclient.ErrNotFound))
+			_, err = client.ListOryAccessControlPolicy(ketoclient.Exact, &ketoclient.ListORYAccessPolicyRequest{})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = client.GetOryAccessControlRole(ketoclient.Exact, "role1")
+			Expect(err).ToNot(HaveOccurred())
+
+			id := fmt.Sprintf("policy-%d", i)
+			_, err = client.UpsertOryAccessControlPolicy(ketoclient.Exact, &ketoclient.UpsertORYAccessPolicyRequest{
+				ORYAccessControlPolicy: ketoclient.ORYAccessControlPolicy{ID: id},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.DeleteOryAccessControlPolicy(ketoclient.Exact, id)).To(Succeed())
+
+			_, err = client.HealthAlive()
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(atomic.LoadInt64(&connections)).To(Equal(int64(1)))
+		Eventually(runtime.NumGoroutine).Should(BeNumerically("<=", goroutines))
+	})
+
+	It("should reuse the connection after the unexpected responses", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Reason", "short and stout")
+			w.WriteHeader(http.StatusTeapot)
+			_, _ = w.Write([]byte(`{"error":"I'm a teapot"}`))
+		}
+
+		for i := 0; i < 20; i++ {
+			_, err := client.GetOryAccessControlRole(ketoclient.Exact, "role1")
+			Expect(err).To(BeAssignableToTypeOf(&ketoclient.UnexpectedResponse{}))
+
+			unexpected := err.(*ketoclient.UnexpectedResponse)
+			Expect(unexpected.StatusCode).To(Equal(http.StatusTeapot))
+			Expect(unexpected.Status).To(Equal("418 I'm a teapot"))
+			Expect(unexpected.Header.Get("X-Reason")).To(Equal("short and stout"))
+			Expect(string(unexpected.Body)).To(Equal(`{"error":"I'm a teapot"}`))
+		}
+
+		Expect(atomic.LoadInt64(&connections)).To(Equal(int64(1)))
+	})
+
+	It("should keep only the beginning of the large unexpected bodies", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(strings.Repeat("x", 1<<20)))
+		}
+
+		_, err := client.HealthReadness()
+		Expect(err).To(BeAssignableToTypeOf(&ketoclient.UnexpectedResponse{}))
+		Expect(err.(*ketoclient.UnexpectedResponse).Body).To(HaveLen(4 << 10))
+	})
+})
@@ -0,0 +1,245 @@
+package ketoclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// route describes an endpoint of the Keto API: how its requests are built and
+// how the statuses of its responses are handled.
+//
+// The statuses are handled the same way by all the routes: the accepted
+// statuses are decoded as the result, 404 fails with `ErrNotFound` for the
+// routes of a single resource and any other status fails with the
+// `ResponseError` sent by the server or, when there is none, with an
+// `UnexpectedResponse`.
+type route[T any] struct {
+	category Category
+	method   string
+
+	// path is the template of the path, whose `{name}` segments are replaced
+	// by the escaped parameters.
+	path string
+
+	// statuses are the statuses of the successful responses.
+	statuses []int
+
+	// notFound makes a 404 fail with `ErrNotFound`.
+	notFound bool
+
+	// decode builds the result of a successful response. It is nil for the
+	// routes without result.
+	decode func(response *http.Response) (T, error)
+}
+
+// pathParams are the values of the segments of the path template of a route.
+type pathParams map[string]string
+
+// call sends a request to the route, encoding the body as JSON, and handles
+// its response.
+func (r *route[T]) call(client *Client, params pathParams, query url.Values, body interface{}) (T, error) {
+	path := r.path
+	for name, value := range params {
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(value))
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		buf := bytes.NewBuffer(nil)
+		err := json.NewEncoder(buf).Encode(body)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		reader = buf
+	}
+
+	return invoke(client, r.category, r.method, path, reader, r.handle)
+}
+
+// handle turns the response into the result or the error of the route.
+func (r *route[T]) handle(response *http.Response) (T, error) {
+	var zero T
+	for _, status := range r.statuses {
+		if response.StatusCode != status {
+			continue
+		}
+		if r.decode == nil {
+			return zero, nil
+		}
+		return r.decode(response)
+	}
+	if response.StatusCode == http.StatusNotFound && r.notFound {
+		return zero, ErrNotFound
+	}
+	return zero, failure(response)
+}
+
+// decodeJSON creates a decoder of the body of the responses into the value
+// returned by `field` for a new result.
+func decodeJSON[T any](field func(r *T) interface{}) func(response *http.Response) (*T, error) {
+	return func(response *http.Response) (*T, error) {
+		r := new(T)
+		err := json.NewDecoder(response.Body).Decode(field(r))
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+}
+
+var (
+	routeAllowed = &route[*AllowedORYAccessControlPolicyResponse]{
+		category: CategoryCheck,
+		method:   http.MethodPost,
+		path:     "/engines/acp/ory/{flavor}/allowed",
+		statuses: []int{http.StatusOK, http.StatusForbidden},
+		decode: func(response *http.Response) (*AllowedORYAccessControlPolicyResponse, error) {
+			return &AllowedORYAccessControlPolicyResponse{Allowed: response.StatusCode == http.StatusOK}, nil
+		},
+	}
+
+	routeUpsertPolicy = &route[*UpsertORYAccessPolicyResponseOK]{
+		category: CategoryWrite,
+		method:   http.MethodPut,
+		path:     "/engines/acp/ory/{flavor}/policies",
+		statuses: []int{http.StatusOK},
+		decode: decodeJSON(func(r *UpsertORYAccessPolicyResponseOK) interface{} {
+			return r
+		}),
+	}
+
+	routeListPolicies = &route[*ListORYAccessPolicyResponseOK]{
+		category: CategoryRead,
+		method:   http.MethodGet,
+		path:     "/engines/acp/ory/{flavor}/policies",
+		statuses: []int{http.StatusOK},
+		decode: decodeJSON(func(r *ListORYAccessPolicyResponseOK) interface{} {
+			return &r.Policies
+		}),
+	}
+
+	routeGetPolicy = &route[*GetORYAccessPolicyResponseOK]{
+		category: CategoryRead,
+		method:   http.MethodGet,
+		path:     "/engines/acp/ory/{flavor}/policies/{id}",
+		statuses: []int{http.StatusOK},
+		notFound: true,
+		decode: decodeJSON(func(r *GetORYAccessPolicyResponseOK) interface{} {
+			return &r.Policy
+		}),
+	}
+
+	routeDeletePolicy = &route[interface{}]{
+		category: CategoryWrite,
+		method:   http.MethodDelete,
+		path:     "/engines/acp/ory/{flavor}/policies/{id}",
+		statuses: []int{http.StatusNoContent, http.StatusOK},
+	}
+
+	routeUpsertRole = &route[*UpsertORYAccessRoleResponseOK]{
+		category: CategoryWrite,
+		method:   http.MethodPut,
+		path:     "/engines/acp/ory/{flavor}/roles",
+		statuses: []int{http.StatusOK},
+		decode: decodeJSON(func(r *UpsertORYAccessRoleResponseOK) interface{} {
+			return &r.Role
+		}),
+	}
+
+	routeListRoles = &route[*ListORYAccessRoleResponseOK]{
+		category: CategoryRead,
+		method:   http.MethodGet,
+		path:     "/engines/acp/ory/{flavor}/roles",
+		statuses: []int{http.StatusOK},
+		decode: decodeJSON(func(r *ListORYAccessRoleResponseOK) interface{} {
+			return &r.Roles
+		}),
+	}
+
+	routeGetRole = &route[*GetORYAccessRoleResponseOK]{
+		category: CategoryRead,
+		method:   http.MethodGet,
+		path:     "/engines/acp/ory/{flavor}/roles/{id}",
+		statuses: []int{http.StatusOK},
+		notFound: true,
+		decode: decodeJSON(func(r *GetORYAccessRoleResponseOK) interface{} {
+			return &r.Role
+		}),
+	}
+
+	routeDeleteRole = &route[interface{}]{
+		category: CategoryWrite,
+		method:   http.MethodDelete,
+		path:     "/engines/acp/ory/{flavor}/roles/{id}",
+		statuses: []int{http.StatusNoContent, http.StatusOK},
+	}
+
+	routeAddMembers = &route[*AddMembersORYAccessRoleResponseOK]{
+		category: CategoryWrite,
+		method:   http.MethodPut,
+		path:     "/engines/acp/ory/{flavor}/roles/{id}/members",
+		statuses: []int{http.StatusOK},
+		decode: decodeJSON(func(r *AddMembersORYAccessRoleResponseOK) interface{} {
+			return &r.Role
+		}),
+	}
+
+	routeRemoveMember = &route[interface{}]{
+		category: CategoryWrite,
+		method:   http.MethodDelete,
+		path:     "/engines/acp/ory/{flavor}/roles/{id}/members/{member}",
+		statuses: []int{http.StatusNoContent, http.StatusOK},
+	}
+
+	routeHealthAlive = &route[*HealthAliveResponse]{
+		category: CategoryHealth,
+		method:   http.MethodGet,
+		path:     "/health/alive",
+		statuses: []int{http.StatusOK},
+		decode: decodeJSON(func(r *HealthAliveResponse) interface{} {
+			return r
+		}),
+	}
+
+	routeHealthReady = &route[*HealthReadnessResponse]{
+		category: CategoryHealth,
+		method:   http.MethodGet,
+		path:     "/health/ready",
+		statuses: []int{http.StatusOK},
+		decode: decodeJSON(func(r *HealthReadnessResponse) interface{} {
+			return r
+		}),
+	}
+
+	routeVersion = &route[*VersionResponse]{
+		category: CategoryHealth,
+		method:   http.MethodGet,
+		path:     "/version",
+		statuses: []int{http.StatusOK},
+		decode: decodeJSON(func(r *VersionResponse) interface{} {
+			return r
+		}),
+	}
+)
+
+// pageQuery builds the query of the paginated routes, leaving out the zero
+// values.
+func pageQuery(limit, offset int64) url.Values {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.FormatInt(limit, 10))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.FormatInt(offset, 10))
+	}
+	return query
+}
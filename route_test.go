@@ -0,0 +1,171 @@
+package ketoclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+var _ = Describe("route", func() {
+	var (
+		server *httptest.Server
+		client *Client
+		status int
+		body   string
+		paths  chan string
+	)
+
+	BeforeEach(func() {
+		paths = make(chan string, 1)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths <- r.URL.RawPath
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		client = New(WithURL(u), WithBreakerPrefix(fmt.Sprintf("ketoclient-test-%d", time.Now().UnixNano())))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	operations := map[string]func(client *Client) error{
+		"AllowedOryAccessControlPolicy": func(client *Client) error {
+			_, err := client.AllowedOryAccessControlPolicy(Exact, &AllowedORYAccessControlPolicyRequest{
+				Subject:  "user:snake-eyes",
+				Action:   "delete",
+				Resource: "blog1:post:33",
+			})
+			return err
+		},
+		"UpsertOryAccessControlPolicy": func(client *Client) error {
+			_, err := client.UpsertOryAccessControlPolicy(Exact, &UpsertORYAccessPolicyRequest{
+				ORYAccessControlPolicy: ORYAccessControlPolicy{ID: "policy1"},
+			})
+			return err
+		},
+		"ListOryAccessControlPolicy": func(client *Client) error {
+			_, err := client.ListOryAccessControlPolicy(Exact, &ListORYAccessPolicyRequest{})
+			return err
+		},
+		"GetOryAccessControlPolicy": func(client *Client) error {
+			_, err := client.GetOryAccessControlPolicy(Exact, "policy1")
+			return err
+		},
+		"DeleteOryAccessControlPolicy": func(client *Client) error {
+			return client.DeleteOryAccessControlPolicy(Exact, "policy1")
+		},
+		"UpsertOryAccessControlRole": func(client *Client) error {
+			_, err := client.UpsertOryAccessControlRole(Exact, &UpsertORYAccessRoleRequest{
+				Role: ORYAccessControlRole{ID: "role1"},
+			})
+			return err
+		},
+		"ListOryAccessControlRole": func(client *Client) error {
+			_, err := client.ListOryAccessControlRole(Exact, &ListORYAccessRoleRequest{})
+			return err
+		},
+		"GetOryAccessControlRole": func(client *Client) error {
+			_, err := client.GetOryAccessControlRole(Exact, "role1")
+			return err
+		},
+		"DeleteOryAccessControlRole": func(client *Client) error {
+			return client.DeleteOryAccessControlRole(Exact, "role1")
+		},
+		"AddMembersOryAccessControlRole": func(client *Client) error {
+			_, err := client.AddMembersOryAccessControlRole(Exact, "role1", &AddMembersORYAccessRoleRequest{
+				Members: []string{"user:snake-eyes"},
+			})
+			return err
+		},
+		"RemoveMemberOryAccessControlRole": func(client *Client) error {
+			return client.RemoveMemberOryAccessControlRole(Exact, "role1", "user:snake-eyes")
+		},
+		"HealthAlive": func(client *Client) error {
+			_, err := client.HealthAlive()
+			return err
+		},
+		"HealthReadness": func(client *Client) error {
+			_, err := client.HealthReadness()
+			return err
+		},
+		"Version": func(client *Client) error {
+			_, err := client.Version()
+			return err
+		},
+	}
+
+	const (
+		object      = `{"id":"policy1","members":[]}`
+		list        = `[]`
+		keto500     = `{"code":500,"message":"database is down"}`
+		keto503     = `{"code":503,"message":"database not ready"}`
+		keto400     = `{"code":400,"message":"invalid policy"}`
+		notKetoBody = `<html>bad gateway</html>`
+	)
+
+	unexpected := BeAssignableToTypeOf(&UnexpectedResponse{})
+	responseError := BeAssignableToTypeOf(&ResponseError{})
+
+	table.DescribeTable("should handle the statuses consistently",
+		func(operation string, s int, b string, expected types.GomegaMatcher) {
+			status, body = s, b
+			Expect(operations[operation](client)).To(expected)
+		},
+		table.Entry("allowed 200", "AllowedOryAccessControlPolicy", 200, ``, Succeed()),
+		table.Entry("allowed 403", "AllowedOryAccessControlPolicy", 403, ``, Succeed()),
+		table.Entry("allowed 404", "AllowedOryAccessControlPolicy", 404, ``, unexpected),
+		table.Entry("allowed 500", "AllowedOryAccessControlPolicy", 500, keto500, responseError),
+		table.Entry("upsert policy 200", "UpsertOryAccessControlPolicy", 200, object, Succeed()),
+		table.Entry("upsert policy 400", "UpsertOryAccessControlPolicy", 400, keto400, responseError),
+		table.Entry("upsert policy 201", "UpsertOryAccessControlPolicy", 201, object, unexpected),
+		table.Entry("list policies 200", "ListOryAccessControlPolicy", 200, list, Succeed()),
+		table.Entry("list policies 500", "ListOryAccessControlPolicy", 500, keto500, responseError),
+		table.Entry("get policy 200", "GetOryAccessControlPolicy", 200, object, Succeed()),
+		table.Entry("get policy 404", "GetOryAccessControlPolicy", 404, ``, MatchError(ErrNotFound)),
+		table.Entry("get policy 500", "GetOryAccessControlPolicy", 500, keto500, responseError),
+		table.Entry("delete policy 204", "DeleteOryAccessControlPolicy", 204, ``, Succeed()),
+		table.Entry("delete policy 200", "DeleteOryAccessControlPolicy", 200, ``, Succeed()),
+		table.Entry("delete policy 404", "DeleteOryAccessControlPolicy", 404, ``, unexpected),
+		table.Entry("upsert role 200", "UpsertOryAccessControlRole", 200, object, Succeed()),
+		table.Entry("upsert role 500", "UpsertOryAccessControlRole", 500, keto500, responseError),
+		table.Entry("list roles 200", "ListOryAccessControlRole", 200, list, Succeed()),
+		table.Entry("get role 200", "GetOryAccessControlRole", 200, object, Succeed()),
+		table.Entry("get role 404", "GetOryAccessControlRole", 404, ``, MatchError(ErrNotFound)),
+		table.Entry("delete role 204", "DeleteOryAccessControlRole", 204, ``, Succeed()),
+		table.Entry("delete role 200", "DeleteOryAccessControlRole", 200, ``, Succeed()),
+		table.Entry("add members 200", "AddMembersOryAccessControlRole", 200, object, Succeed()),
+		table.Entry("add members 500", "AddMembersOryAccessControlRole", 500, keto500, responseError),
+		table.Entry("remove member 204", "RemoveMemberOryAccessControlRole", 204, ``, Succeed()),
+		table.Entry("remove member 200", "RemoveMemberOryAccessControlRole", 200, ``, Succeed()),
+		table.Entry("remove member 500", "RemoveMemberOryAccessControlRole", 500, keto500, responseError),
+		table.Entry("health alive 200", "HealthAlive", 200, `{"status":"ok"}`, Succeed()),
+		table.Entry("health alive 503", "HealthAlive", 503, keto503, responseError),
+		table.Entry("health ready 503", "HealthReadness", 503, keto503, responseError),
+		table.Entry("health ready 502 without error body", "HealthReadness", 502, notKetoBody, unexpected),
+		table.Entry("version 200", "Version", 200, `{"version":"v0.3.3"}`, Succeed()),
+		table.Entry("version 500", "Version", 500, keto500, responseError),
+		table.Entry("version 500 without body", "Version", 500, ``, unexpected),
+	)
+
+	It("should escape the parameters of the path", func() {
+		status = http.StatusNoContent
+		Expect(client.RemoveMemberOryAccessControlRole(Exact, "role1", "group/admins")).To(Succeed())
+		Expect(<-paths).To(Equal("/engines/acp/ory/exact/roles/role1/members/group%2Fadmins"))
+	})
+
+	It("should add the pagination to the query", func() {
+		Expect(pageQuery(0, 0).Encode()).To(BeEmpty())
+		Expect(pageQuery(10, 0).Encode()).To(Equal("limit=10"))
+		Expect(pageQuery(10, 20).Encode()).To(Equal("limit=10&offset=20"))
+	})
+})
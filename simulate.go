@@ -0,0 +1,92 @@
+package ketoclient
+
+// PolicySet groups the policies and roles of a flavor.
+type PolicySet struct {
+	Policies []ORYAccessControlPolicy `json:"policies"`
+	Roles    []ORYAccessControlRole   `json:"roles"`
+}
+
+// DecisionChange is a request whose decision differs between the current and
+// the proposed policy sets.
+type DecisionChange struct {
+	Request  *AllowedORYAccessControlPolicyRequest `json:"request"`
+	Current  *Explanation                          `json:"current"`
+	Proposed *Explanation                          `json:"proposed"`
+}
+
+// Simulation is the report of running a corpus of requests against the
+// current and the proposed policy sets.
+type Simulation struct {
+	Flavor          Flavor           `json:"flavor"`
+	Requests        int              `json:"requests"`
+	Unchanged       int              `json:"unchanged"`
+	AllowedToDenied []DecisionChange `json:"allowed_to_denied"`
+	DeniedToAllowed []DecisionChange `json:"denied_to_allowed"`
+}
+
+// Changed reports if any decision would flip.
+func (s *Simulation) Changed() bool {
+	return len(s.AllowedToDenied) > 0 || len(s.DeniedToAllowed) > 0
+}
+
+// SimulateOryAccessControlPolicy runs the requests against the live policies
+// and roles of the flavor and against the proposed set, reporting which
+// decisions would flip.
+//
+// When the proposed set has no roles, the live roles are used for both sides.
+// Nothing is written to the server.
+func (client *Client) SimulateOryAccessControlPolicy(flavor Flavor, proposed *PolicySet, requests []*AllowedORYAccessControlPolicyRequest) (*Simulation, error) {
+	policies, err := client.listAllOryAccessControlPolicies(flavor)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := client.listAllOryAccessControlRoles(flavor)
+	if err != nil {
+		return nil, err
+	}
+	return Simulate(flavor, &PolicySet{Policies: policies, Roles: roles}, proposed, requests)
+}
+
+// Simulate runs the requests against both policy sets, fully client-side.
+//
+// When the proposed set has no roles, the roles of the current set are used.
+func Simulate(flavor Flavor, current, proposed *PolicySet, requests []*AllowedORYAccessControlPolicyRequest) (*Simulation, error) {
+	proposedRoles := proposed.Roles
+	if proposedRoles == nil {
+		proposedRoles = current.Roles
+	}
+	currentEvaluator := NewEvaluator(flavor, current.Policies, current.Roles)
+	proposedEvaluator := NewEvaluator(flavor, proposed.Policies, proposedRoles)
+
+	r := &Simulation{
+		Flavor:          flavor,
+		Requests:        len(requests),
+		AllowedToDenied: make([]DecisionChange, 0),
+		DeniedToAllowed: make([]DecisionChange, 0),
+	}
+	for _, request := range requests {
+		c, err := currentEvaluator.Explain(request)
+		if err != nil {
+			return nil, err
+		}
+		p, err := proposedEvaluator.Explain(request)
+		if err != nil {
+			return nil, err
+		}
+
+		change := DecisionChange{
+			Request:  request,
+			Current:  c,
+			Proposed: p,
+		}
+		switch {
+		case c.Allowed == p.Allowed:
+			r.Unchanged++
+		case c.Allowed:
+			r.AllowedToDenied = append(r.AllowedToDenied, change)
+		default:
+			r.DeniedToAllowed = append(r.DeniedToAllowed, change)
+		}
+	}
+	return r, nil
+}
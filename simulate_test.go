@@ -0,0 +1,91 @@
+package ketoclient_test
+
+import (
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SimulateOryAccessControlPolicy", func() {
+	var (
+		keto     *fakeKeto
+		requests []*ketoclient.AllowedORYAccessControlPolicyRequest
+	)
+
+	BeforeEach(func() {
+		keto = newFakeKeto()
+		keto.AddRoles(ketoclient.Exact, ketoclient.ORYAccessControlRole{
+			ID:      "role:editors",
+			Members: []string{"user:snake-eyes", "user:scarlet"},
+		})
+		keto.AddPolicies(ketoclient.Exact, ketoclient.ORYAccessControlPolicy{
+			ID:        "allow-editors",
+			Subjects:  []string{"role:editors"},
+			Resources: []string{"blog1:post:33"},
+			Actions:   []string{"edit"},
+			Effect:    ketoclient.Allow,
+		})
+
+		requests = []*ketoclient.AllowedORYAccessControlPolicyRequest{
+			{Subject: "user:snake-eyes", Action: "edit", Resource: "blog1:post:33"},
+			{Subject: "user:scarlet", Action: "edit", Resource: "blog1:post:33"},
+			{Subject: "user:duke", Action: "edit", Resource: "blog1:post:33"},
+			{Subject: "user:duke", Action: "delete", Resource: "blog1:post:33"},
+		}
+	})
+
+	AfterEach(func() {
+		keto.Close()
+	})
+
+	It("should report the decisions that would flip", func() {
+		simulation, err := keto.Client().SimulateOryAccessControlPolicy(ketoclient.Exact, &ketoclient.PolicySet{
+			Policies: []ketoclient.ORYAccessControlPolicy{{
+				ID:        "allow-editors",
+				Subjects:  []string{"role:editors", "user:duke"},
+				Resources: []string{"blog1:post:33"},
+				Actions:   []string{"edit"},
+				Effect:    ketoclient.Allow,
+			}, {
+				ID:        "deny-scarlet",
+				Subjects:  []string{"user:scarlet"},
+				Resources: []string{"blog1:post:33"},
+				Actions:   []string{"edit"},
+				Effect:    ketoclient.Deny,
+			}},
+		}, requests)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(simulation.Changed()).To(BeTrue())
+		Expect(simulation.Requests).To(Equal(4))
+		Expect(simulation.Unchanged).To(Equal(2))
+		Expect(simulation.AllowedToDenied).To(HaveLen(1))
+		Expect(simulation.AllowedToDenied[0].Request.Subject).To(Equal("user:scarlet"))
+		Expect(simulation.AllowedToDenied[0].Proposed.DecidedBy.ID).To(Equal("deny-scarlet"))
+		Expect(simulation.DeniedToAllowed).To(HaveLen(1))
+		Expect(simulation.DeniedToAllowed[0].Request.Subject).To(Equal("user:duke"))
+		Expect(simulation.DeniedToAllowed[0].Current.DecidedBy).To(BeNil())
+	})
+
+	It("should use the proposed roles", func() {
+		simulation, err := keto.Client().SimulateOryAccessControlPolicy(ketoclient.Exact, &ketoclient.PolicySet{
+			Policies: keto.policies[ketoclient.Exact],
+			Roles: []ketoclient.ORYAccessControlRole{{
+				ID:      "role:editors",
+				Members: []string{"user:snake-eyes"},
+			}},
+		}, requests)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(simulation.DeniedToAllowed).To(BeEmpty())
+		Expect(simulation.AllowedToDenied).To(HaveLen(1))
+		Expect(simulation.AllowedToDenied[0].Request.Subject).To(Equal("user:scarlet"))
+	})
+
+	It("should not report changes when the sets are equivalent", func() {
+		simulation, err := keto.Client().SimulateOryAccessControlPolicy(ketoclient.Exact, &ketoclient.PolicySet{
+			Policies: keto.policies[ketoclient.Exact],
+		}, requests)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(simulation.Changed()).To(BeFalse())
+		Expect(simulation.Unchanged).To(Equal(4))
+	})
+})
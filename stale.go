@@ -0,0 +1,162 @@
+package ketoclient
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	hystrixgo "github.com/afex/hystrix-go/hystrix"
+	"github.com/lab259/errors/v2"
+)
+
+// DefaultDecisionStoreSize is the maximum number of decisions kept by the
+// store created when `StaleOnError.Store` is not given.
+const DefaultDecisionStoreSize = 10000
+
+// DecisionStore keeps the last known decision of each check.
+type DecisionStore interface {
+	// Get returns the last decision stored for the key and when it was made.
+	Get(key string) (allowed bool, at time.Time, ok bool)
+
+	// Set stores the decision for the key.
+	Set(key string, allowed bool, at time.Time)
+}
+
+// StaleOnError configures the fallback of the authorization checks when the
+// server fails.
+type StaleOnError struct {
+	// Window is how old a stored decision can be to be served.
+	Window time.Duration
+
+	// DefaultAllowed is the decision applied when there is no stored
+	// decision within the window. The default is to deny.
+	DefaultAllowed bool
+
+	// Store keeps the decisions. The default is an in memory store limited to
+	// `DefaultDecisionStoreSize` decisions.
+	Store DecisionStore
+}
+
+type staleFallback struct {
+	StaleOnError
+}
+
+// resolve stores the successful decisions and, when Keto is down or cannot
+// be reached (see `unavailable`), replaces the error by the last known
+// decision or by the default.
+//
+// The timeout of the call (see `CallTimeout`) expiring also means that Keto
+// is unavailable, as it did not answer in time. The context given is the one
+// of the caller, without that timeout.
+//
+// The other failures, a rejected request or credential for instance, those
+// caused by the caller context, and the failures of the calls that must not
+// be served from the store are returned as they are, so a misconfiguration
+// never turns into a decision.
+func (s *staleFallback) resolve(ctx context.Context, flavor Flavor, request *AllowedORYAccessControlPolicyRequest, response *AllowedORYAccessControlPolicyResponse, err error, fallback bool) (*AllowedORYAccessControlPolicyResponse, error) {
+	key, keyErr := decisionKey(flavor, request)
+	if keyErr != nil {
+		if err == nil {
+			err = keyErr
+		}
+		return response, err
+	}
+
+	if err == nil {
+		s.Store.Set(key, response.Allowed, time.Now())
+		return response, nil
+	}
+	if !fallback || ctx.Err() != nil || !(unavailable(err) || errors.Is(err, context.DeadlineExceeded)) {
+		return nil, err
+	}
+
+	if allowed, at, ok := s.Store.Get(key); ok && time.Since(at) <= s.Window {
+		return &AllowedORYAccessControlPolicyResponse{Allowed: allowed, Stale: true}, nil
+	}
+	return &AllowedORYAccessControlPolicyResponse{Allowed: s.DefaultAllowed, Defaulted: true}, nil
+}
+
+// unavailable reports whether the error tells that Keto is down or cannot be
+// reached: a transport failure, an open circuit, a throttled request or a 5xx
+// response.
+func unavailable(err error) bool {
+	switch err := err.(type) {
+	case *url.Error, net.Error, hystrixgo.CircuitError:
+		return true
+	case *ResponseError:
+		return err.Code >= http.StatusInternalServerError
+	case *UnexpectedResponse:
+		return err.StatusCode >= http.StatusInternalServerError
+	}
+	return errors.Is(err, ErrThrottled)
+}
+
+// decisionKey identifies a check: the flavor, subject, action, resource and
+// the context in its canonical JSON form.
+func decisionKey(flavor Flavor, request *AllowedORYAccessControlPolicyRequest) (string, error) {
+	var context interface{}
+	if request.Context != nil {
+		if err := normalizeJSON(request.Context, &context); err != nil {
+			return "", err
+		}
+	}
+	data, err := json.Marshal([]interface{}{flavor, request.Subject, request.Action, request.Resource, context})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+type storedDecision struct {
+	allowed bool
+	at      time.Time
+}
+
+// memoryDecisionStore is a `DecisionStore` limited to a number of decisions.
+// When it is full, the oldest decision is replaced.
+type memoryDecisionStore struct {
+	mu        sync.Mutex
+	size      int
+	decisions map[string]storedDecision
+	order     []string
+	next      int
+}
+
+// NewMemoryDecisionStore creates an in memory `DecisionStore` that keeps up to
+// `size` decisions, or `DefaultDecisionStoreSize` when it is not positive.
+func NewMemoryDecisionStore(size int) DecisionStore {
+	if size <= 0 {
+		size = DefaultDecisionStoreSize
+	}
+	return &memoryDecisionStore{
+		size:      size,
+		decisions: make(map[string]storedDecision, size),
+		order:     make([]string, 0, size),
+	}
+}
+
+func (s *memoryDecisionStore) Get(key string) (bool, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.decisions[key]
+	return d.allowed, d.at, ok
+}
+
+func (s *memoryDecisionStore) Set(key string, allowed bool, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.decisions[key]; !ok {
+		if len(s.order) < s.size {
+			s.order = append(s.order, key)
+		} else {
+			delete(s.decisions, s.order[s.next])
+			s.order[s.next] = key
+			s.next = (s.next + 1) % s.size
+		}
+	}
+	s.decisions[key] = storedDecision{allowed: allowed, at: at}
+}
@@ -0,0 +1,212 @@
+package ketoclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StaleOnError", func() {
+	var (
+		server *httptest.Server
+		u      *url.URL
+		down   int32
+	)
+
+	request := func(resource string) *ketoclient.AllowedORYAccessControlPolicyRequest {
+		return &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: resource,
+			Context:  map[string]interface{}{"b": 1, "a": 2},
+		}
+	}
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&down, 0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.LoadInt32(&down) == 1 {
+				writeJSON(w, http.StatusInternalServerError, &ketoclient.ResponseError{Code: 500, Message: "database is down"})
+				return
+			}
+			writeJSON(w, http.StatusOK, &ketoclient.AllowedORYAccessControlPolicyResponse{Allowed: true})
+		}))
+
+		var err error
+		u, err = url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should fail without the fallback", func() {
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix())
+		atomic.StoreInt32(&down, 1)
+		_, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:33"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should serve the last known decision", func() {
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithStaleOnError(ketoclient.StaleOnError{
+			Window: time.Minute,
+		}))
+
+		response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:33"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Stale).To(BeFalse())
+
+		atomic.StoreInt32(&down, 1)
+		response, err = client.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: "blog1:post:33",
+			Context:  map[string]interface{}{"a": 2, "b": 1},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Stale).To(BeTrue())
+		Expect(response.Defaulted).To(BeFalse())
+	})
+
+	It("should apply the default when there is no recent decision", func() {
+		store := ketoclient.NewMemoryDecisionStore(10)
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithStaleOnError(ketoclient.StaleOnError{
+			Window: time.Minute,
+			Store:  store,
+		}))
+
+		_, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:33"))
+		Expect(err).ToNot(HaveOccurred())
+
+		atomic.StoreInt32(&down, 1)
+		response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:34"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Defaulted).To(BeTrue())
+	})
+
+	It("should not serve decisions older than the window", func() {
+		store := ketoclient.NewMemoryDecisionStore(10)
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithStaleOnError(ketoclient.StaleOnError{
+			Window:         time.Minute,
+			DefaultAllowed: false,
+			Store:          store,
+		}))
+
+		_, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:33"))
+		Expect(err).ToNot(HaveOccurred())
+		allowed, at, ok := store.Get(`["exact","user:snake-eyes","delete","blog1:post:33",{"a":2,"b":1}]`)
+		Expect(ok).To(BeTrue())
+		Expect(allowed).To(BeTrue())
+		store.Set(`["exact","user:snake-eyes","delete","blog1:post:33",{"a":2,"b":1}]`, true, at.Add(-2*time.Minute))
+
+		atomic.StoreInt32(&down, 1)
+		response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:33"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Defaulted).To(BeTrue())
+	})
+
+	It("should not fall back when the caller context is done", func() {
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithStaleOnError(ketoclient.StaleOnError{
+			Window: time.Minute,
+		}))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := client.WithContext(ctx).AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:33"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fall back when the call times out on a slow server", func() {
+		var slow int32
+		hung := make(chan struct{})
+		slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.LoadInt32(&slow) == 1 {
+				<-hung
+			}
+			writeJSON(w, http.StatusOK, &ketoclient.AllowedORYAccessControlPolicyResponse{Allowed: true})
+		}))
+		defer slowServer.Close()
+		defer close(hung)
+		u, err := url.Parse(slowServer.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithStaleOnError(ketoclient.StaleOnError{
+			Window: time.Minute,
+		}), ketoclient.WithCallOptions(ketoclient.CallTimeout(50*time.Millisecond)))
+		_, err = client.AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:33"))
+		Expect(err).ToNot(HaveOccurred())
+
+		atomic.StoreInt32(&slow, 1)
+		response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:33"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Stale).To(BeTrue())
+
+		response, err = client.AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:34"), ketoclient.CallTimeout(20*time.Millisecond))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Defaulted).To(BeTrue())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = client.WithContext(ctx).AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:33"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fall back when the server cannot be reached", func() {
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithStaleOnError(ketoclient.StaleOnError{
+			Window:         time.Minute,
+			DefaultAllowed: true,
+		}))
+		server.Close()
+
+		response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:33"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Defaulted).To(BeTrue())
+	})
+
+	It("should not fall back when the server rejects the request", func() {
+		unauthorized := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusUnauthorized, &ketoclient.ResponseError{Code: 401, Message: "access credentials are invalid"})
+		}))
+		defer unauthorized.Close()
+		u, err := url.Parse(unauthorized.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithStaleOnError(ketoclient.StaleOnError{
+			Window:         time.Minute,
+			DefaultAllowed: true,
+		}))
+		response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, request("blog1:post:33"))
+		Expect(err).To(BeAssignableToTypeOf(&ketoclient.ResponseError{}))
+		Expect(err.(*ketoclient.ResponseError).Code).To(BeEquivalentTo(401))
+		Expect(response).To(BeNil())
+	})
+
+	It("should evict the oldest decisions when the store is full", func() {
+		store := ketoclient.NewMemoryDecisionStore(2)
+		now := time.Now()
+		store.Set("a", true, now)
+		store.Set("b", true, now)
+		store.Set("a", false, now)
+		store.Set("c", true, now)
+
+		_, _, ok := store.Get("a")
+		Expect(ok).To(BeFalse())
+		_, _, ok = store.Get("b")
+		Expect(ok).To(BeTrue())
+		_, _, ok = store.Get("c")
+		Expect(ok).To(BeTrue())
+	})
+})
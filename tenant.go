@@ -0,0 +1,408 @@
+package ketoclient
+
+import (
+	"strings"
+
+	"github.com/lab259/errors/v2"
+)
+
+// DefaultTenantSeparator separates the tenant from the namespaced value. With
+// the glob flavor it is also the pattern delimiter, so wildcards cannot reach
+// other tenants.
+const DefaultTenantSeparator = ":"
+
+var (
+	ErrInvalidTenant        = errors.New("invalid tenant")
+	ErrCrossTenant          = errors.New("operation crosses tenant boundaries")
+	ErrUnsupportedCondition = errors.New("condition not supported by the tenant client")
+)
+
+// TenantClient is a view of a `Client` scoped to a single tenant.
+//
+// It transparently prefixes the policy IDs, role IDs, subjects, resources
+// and role members with the tenant, strips the prefix from the responses and
+// filters the listings to the tenant. Any response that refers to data
+// outside of the tenant fails with `ErrCrossTenant`.
+//
+// The arguments are validated as by the `Client`, before being prefixed, so
+// an empty subject or ID fails with a `*ValidationError`.
+//
+// The values of the request context are not prefixed, so a condition
+// comparing them against the prefixed subject could never be fulfilled. The
+// policies with an `EqualsSubjectCondition` are refused with
+// `ErrUnsupportedCondition`.
+type TenantClient struct {
+	client *Client
+	tenant string
+	prefix string
+}
+
+// Tenant creates a view of the client scoped to the tenant, using the
+// `DefaultTenantSeparator`.
+func (client *Client) Tenant(tenant string) (*TenantClient, error) {
+	return NewTenantClient(client, tenant, DefaultTenantSeparator)
+}
+
+// NewTenantClient creates a view of the client scoped to the tenant.
+//
+// The tenant cannot be empty nor contain the separator or any pattern special
+// character.
+func NewTenantClient(client *Client, tenant, separator string) (*TenantClient, error) {
+	if tenant == "" || separator == "" || strings.Contains(tenant, separator) || strings.ContainsAny(tenant, `/*?[]{}<>\`) {
+		return nil, errors.Wrap(ErrInvalidTenant, errors.Message(tenant))
+	}
+	return &TenantClient{
+		client: client,
+		tenant: tenant,
+		prefix: tenant + separator,
+	}, nil
+}
+
+// Name returns the tenant name.
+func (t *TenantClient) Name() string {
+	return t.tenant
+}
+
+// AllowedOryAccessControlPolicy check if a request is allowed within the
+// tenant.
+func (t *TenantClient) AllowedOryAccessControlPolicy(flavor Flavor, request *AllowedORYAccessControlPolicyRequest, opts ...CallOption) (*AllowedORYAccessControlPolicyResponse, error) {
+	if err := validateAllowed(flavor, request); err != nil {
+		return nil, err
+	}
+	r := *request
+	r.Subject = t.add(request.Subject)
+	r.Resource = t.add(request.Resource)
+	return t.client.AllowedOryAccessControlPolicy(flavor, &r, opts...)
+}
+
+// UpsertOryAccessControlPolicy an ORY Access Control Policy of the tenant.
+func (t *TenantClient) UpsertOryAccessControlPolicy(flavor Flavor, request *UpsertORYAccessPolicyRequest, opts ...CallOption) (*UpsertORYAccessPolicyResponseOK, error) {
+	if err := validateUpsertPolicy(flavor, request); err != nil {
+		return nil, err
+	}
+	policy, err := t.addPolicy(request.ORYAccessControlPolicy)
+	if err != nil {
+		return nil, err
+	}
+	response, err := t.client.UpsertOryAccessControlPolicy(flavor, &UpsertORYAccessPolicyRequest{ORYAccessControlPolicy: policy}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if response.ORYAccessControlPolicy != nil {
+		policy, err := t.stripPolicy(*response.ORYAccessControlPolicy)
+		if err != nil {
+			return nil, err
+		}
+		response.ORYAccessControlPolicy = &policy
+	}
+	return response, nil
+}
+
+// ListOryAccessControlPolicy list the ORY Access Control Policies of the
+// tenant.
+//
+// As the server cannot filter by tenant, all policies are fetched and the
+// pagination is applied after filtering.
+func (t *TenantClient) ListOryAccessControlPolicy(flavor Flavor, request *ListORYAccessPolicyRequest, opts ...CallOption) (*ListORYAccessPolicyResponseOK, error) {
+	if err := validateListPolicies(flavor, request); err != nil {
+		return nil, err
+	}
+	all, err := t.client.listAllOryAccessControlPolicies(flavor, opts...)
+	if err != nil {
+		return nil, err
+	}
+	policies := make([]ORYAccessControlPolicy, 0, len(all))
+	for _, policy := range all {
+		if !strings.HasPrefix(policy.ID, t.prefix) {
+			continue
+		}
+		p, err := t.stripPolicy(policy)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	from, to := paginate(len(policies), request.Limit, request.Offset)
+	return &ListORYAccessPolicyResponseOK{Policies: policies[from:to]}, nil
+}
+
+// GetOryAccessControlPolicy returns an ORY Access Control Policy of the
+// tenant.
+func (t *TenantClient) GetOryAccessControlPolicy(flavor Flavor, id string, opts ...CallOption) (*GetORYAccessPolicyResponseOK, error) {
+	if err := validateID("GetOryAccessControlPolicy", flavor, id); err != nil {
+		return nil, err
+	}
+	id, err := t.addID(id)
+	if err != nil {
+		return nil, err
+	}
+	response, err := t.client.GetOryAccessControlPolicy(flavor, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	response.Policy, err = t.stripPolicy(response.Policy)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// DeleteOryAccessControlPolicy deletes an ORY Access Control Policy of the
+// tenant.
+func (t *TenantClient) DeleteOryAccessControlPolicy(flavor Flavor, id string, opts ...CallOption) error {
+	if err := validateID("DeleteOryAccessControlPolicy", flavor, id); err != nil {
+		return err
+	}
+	id, err := t.addID(id)
+	if err != nil {
+		return err
+	}
+	return t.client.DeleteOryAccessControlPolicy(flavor, id, opts...)
+}
+
+// UpsertOryAccessControlRole update or insert an ORY Access Control Role of
+// the tenant.
+func (t *TenantClient) UpsertOryAccessControlRole(flavor Flavor, request *UpsertORYAccessRoleRequest, opts ...CallOption) (*UpsertORYAccessRoleResponseOK, error) {
+	if err := validateUpsertRole(flavor, request); err != nil {
+		return nil, err
+	}
+	role, err := t.addRole(request.Role)
+	if err != nil {
+		return nil, err
+	}
+	response, err := t.client.UpsertOryAccessControlRole(flavor, &UpsertORYAccessRoleRequest{Role: role}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	response.Role, err = t.stripRole(response.Role)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetOryAccessControlRole returns an ORY Access Control Role of the tenant.
+func (t *TenantClient) GetOryAccessControlRole(flavor Flavor, id string, opts ...CallOption) (*GetORYAccessRoleResponseOK, error) {
+	if err := validateID("GetOryAccessControlRole", flavor, id); err != nil {
+		return nil, err
+	}
+	id, err := t.addID(id)
+	if err != nil {
+		return nil, err
+	}
+	response, err := t.client.GetOryAccessControlRole(flavor, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	response.Role, err = t.stripRole(response.Role)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ListOryAccessControlRole list the ORY Access Control Roles of the tenant.
+//
+// As the server cannot filter by tenant, all roles are fetched and the
+// pagination is applied after filtering.
+func (t *TenantClient) ListOryAccessControlRole(flavor Flavor, request *ListORYAccessRoleRequest, opts ...CallOption) (*ListORYAccessRoleResponseOK, error) {
+	if err := validateListRoles(flavor, request); err != nil {
+		return nil, err
+	}
+	all, err := t.client.listAllOryAccessControlRoles(flavor, opts...)
+	if err != nil {
+		return nil, err
+	}
+	roles := make([]ORYAccessControlRole, 0, len(all))
+	for _, role := range all {
+		if !strings.HasPrefix(role.ID, t.prefix) {
+			continue
+		}
+		r, err := t.stripRole(role)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, r)
+	}
+	from, to := paginate(len(roles), request.Limit, request.Offset)
+	return &ListORYAccessRoleResponseOK{Roles: roles[from:to]}, nil
+}
+
+// DeleteOryAccessControlRole deletes an ORY Access Control Role of the
+// tenant.
+func (t *TenantClient) DeleteOryAccessControlRole(flavor Flavor, id string, opts ...CallOption) error {
+	if err := validateID("DeleteOryAccessControlRole", flavor, id); err != nil {
+		return err
+	}
+	id, err := t.addID(id)
+	if err != nil {
+		return err
+	}
+	return t.client.DeleteOryAccessControlRole(flavor, id, opts...)
+}
+
+// AddMembersOryAccessControlRole adds members to an ORY Access Control Role
+// of the tenant.
+func (t *TenantClient) AddMembersOryAccessControlRole(flavor Flavor, id string, request *AddMembersORYAccessRoleRequest, opts ...CallOption) (*AddMembersORYAccessRoleResponseOK, error) {
+	if err := validateAddMembers(flavor, id, request); err != nil {
+		return nil, err
+	}
+	id, err := t.addID(id)
+	if err != nil {
+		return nil, err
+	}
+	response, err := t.client.AddMembersOryAccessControlRole(flavor, id, &AddMembersORYAccessRoleRequest{
+		Members: t.addAll(request.Members),
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	response.Role, err = t.stripRole(response.Role)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// RemoveMemberOryAccessControlRole removes a member from an ORY Access
+// Control Role of the tenant.
+func (t *TenantClient) RemoveMemberOryAccessControlRole(flavor Flavor, id, member string, opts ...CallOption) error {
+	if err := validateRemoveMember(flavor, id, member); err != nil {
+		return err
+	}
+	id, err := t.addID(id)
+	if err != nil {
+		return err
+	}
+	member, err = t.addID(member)
+	if err != nil {
+		return err
+	}
+	return t.client.RemoveMemberOryAccessControlRole(flavor, id, member, opts...)
+}
+
+func (t *TenantClient) add(value string) string {
+	return t.prefix + value
+}
+
+func (t *TenantClient) addAll(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	r := make([]string, len(values))
+	for i, value := range values {
+		r[i] = t.add(value)
+	}
+	return r
+}
+
+// addID prefixes a value that is used as part of the URL path. Values with
+// slashes are refused, otherwise they could reach paths of other tenants.
+func (t *TenantClient) addID(id string) (string, error) {
+	if strings.Contains(id, "/") {
+		return "", errors.Wrap(ErrCrossTenant, errors.Message(id))
+	}
+	return t.add(id), nil
+}
+
+func (t *TenantClient) strip(value string) (string, error) {
+	if !strings.HasPrefix(value, t.prefix) {
+		return "", errors.Wrap(ErrCrossTenant, errors.Message(value))
+	}
+	return value[len(t.prefix):], nil
+}
+
+func (t *TenantClient) stripAll(values []string) ([]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	r := make([]string, len(values))
+	for i, value := range values {
+		v, err := t.strip(value)
+		if err != nil {
+			return nil, err
+		}
+		r[i] = v
+	}
+	return r, nil
+}
+
+func (t *TenantClient) addPolicy(policy ORYAccessControlPolicy) (ORYAccessControlPolicy, error) {
+	if err := checkTenantConditions(policy.Conditions); err != nil {
+		return policy, err
+	}
+	id, err := t.addID(policy.ID)
+	if err != nil {
+		return policy, err
+	}
+	policy.ID = id
+	policy.Subjects = t.addAll(policy.Subjects)
+	policy.Resources = t.addAll(policy.Resources)
+	return policy, nil
+}
+
+// checkTenantConditions refuses the conditions that compare the request
+// context against the subject, as the context is not prefixed.
+func checkTenantConditions(conditions interface{}) error {
+	if !hasConditions(conditions) {
+		return nil
+	}
+	parsed := make(map[string]condition)
+	if err := normalizeJSON(conditions, &parsed); err != nil {
+		return err
+	}
+	for key, c := range parsed {
+		if c.Type == "EqualsSubjectCondition" {
+			return errors.Wrap(ErrUnsupportedCondition, errors.Message(key+": "+c.Type))
+		}
+	}
+	return nil
+}
+
+func (t *TenantClient) stripPolicy(policy ORYAccessControlPolicy) (ORYAccessControlPolicy, error) {
+	var err error
+	if policy.ID, err = t.strip(policy.ID); err != nil {
+		return policy, err
+	}
+	if policy.Subjects, err = t.stripAll(policy.Subjects); err != nil {
+		return policy, err
+	}
+	if policy.Resources, err = t.stripAll(policy.Resources); err != nil {
+		return policy, err
+	}
+	return policy, nil
+}
+
+func (t *TenantClient) addRole(role ORYAccessControlRole) (ORYAccessControlRole, error) {
+	id, err := t.addID(role.ID)
+	if err != nil {
+		return role, err
+	}
+	role.ID = id
+	role.Members = t.addAll(role.Members)
+	return role, nil
+}
+
+func (t *TenantClient) stripRole(role ORYAccessControlRole) (ORYAccessControlRole, error) {
+	var err error
+	if role.ID, err = t.strip(role.ID); err != nil {
+		return role, err
+	}
+	if role.Members, err = t.stripAll(role.Members); err != nil {
+		return role, err
+	}
+	return role, nil
+}
+
+// paginate returns the boundaries of a page over `total` items.
+func paginate(total int, limit, offset int64) (int, int) {
+	from := int(offset)
+	if from > total {
+		from = total
+	}
+	to := total
+	if limit > 0 && from+int(limit) < total {
+		to = from + int(limit)
+	}
+	return from, to
+}
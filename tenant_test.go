@@ -0,0 +1,228 @@
+package ketoclient_test
+
+import (
+	"github.com/lab259/errors/v2"
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TenantClient", func() {
+	var (
+		keto   *fakeKeto
+		tenant *ketoclient.TenantClient
+	)
+
+	BeforeEach(func() {
+		keto = newFakeKeto()
+
+		var err error
+		tenant, err = keto.Client().Tenant("acme")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		keto.Close()
+	})
+
+	It("should refuse invalid tenants", func() {
+		for _, name := range []string{"", "ac:me", "acme*", "ac/me"} {
+			_, err := keto.Client().Tenant(name)
+			Expect(errors.Is(err, ketoclient.ErrInvalidTenant)).To(BeTrue(), name)
+		}
+	})
+
+	It("should prefix the policies with the tenant", func() {
+		response, err := tenant.UpsertOryAccessControlPolicy(ketoclient.Exact, &ketoclient.UpsertORYAccessPolicyRequest{
+			ORYAccessControlPolicy: ketoclient.ORYAccessControlPolicy{
+				ID:        "id1",
+				Subjects:  []string{"user:snake-eyes"},
+				Resources: []string{"blog1:post:33"},
+				Actions:   []string{"delete"},
+				Effect:    ketoclient.Allow,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.ID).To(Equal("id1"))
+		Expect(response.Subjects).To(ConsistOf("user:snake-eyes"))
+
+		stored := keto.policies[ketoclient.Exact][0]
+		Expect(stored.ID).To(Equal("acme:id1"))
+		Expect(stored.Subjects).To(ConsistOf("acme:user:snake-eyes"))
+		Expect(stored.Resources).To(ConsistOf("acme:blog1:post:33"))
+		Expect(stored.Actions).To(ConsistOf("delete"))
+
+		getResponse, err := tenant.GetOryAccessControlPolicy(ketoclient.Exact, "id1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getResponse.Policy.ID).To(Equal("id1"))
+		Expect(getResponse.Policy.Resources).To(ConsistOf("blog1:post:33"))
+
+		allowedResponse, err := tenant.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: "blog1:post:33",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowedResponse.Allowed).To(BeTrue())
+
+		Expect(tenant.DeleteOryAccessControlPolicy(ketoclient.Exact, "id1")).To(Succeed())
+		Expect(keto.policies[ketoclient.Exact]).To(BeEmpty())
+	})
+
+	It("should not allow requests of other tenants", func() {
+		keto.AddPolicies(ketoclient.Glob, ketoclient.ORYAccessControlPolicy{
+			ID:        "other:id1",
+			Subjects:  []string{"other:user:snake-eyes"},
+			Resources: []string{"other:blog1:**"},
+			Actions:   []string{"delete"},
+			Effect:    ketoclient.Allow,
+		})
+
+		response, err := tenant.AllowedOryAccessControlPolicy(ketoclient.Glob, &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: "blog1:post:33",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Allowed).To(BeFalse())
+	})
+
+	It("should filter and paginate the listings to the tenant", func() {
+		keto.AddPolicies(ketoclient.Exact,
+			ketoclient.ORYAccessControlPolicy{ID: "acme:id1", Subjects: []string{"acme:user"}},
+			ketoclient.ORYAccessControlPolicy{ID: "other:id2", Subjects: []string{"other:user"}},
+			ketoclient.ORYAccessControlPolicy{ID: "acme:id3", Subjects: []string{"acme:user"}},
+			ketoclient.ORYAccessControlPolicy{ID: "acme:id4", Subjects: []string{"acme:user"}},
+		)
+		keto.AddRoles(ketoclient.Exact,
+			ketoclient.ORYAccessControlRole{ID: "other:role1", Members: []string{"other:user"}},
+			ketoclient.ORYAccessControlRole{ID: "acme:role2", Members: []string{"acme:user"}},
+		)
+
+		policies, err := tenant.ListOryAccessControlPolicy(ketoclient.Exact, &ketoclient.ListORYAccessPolicyRequest{
+			Limit:  2,
+			Offset: 1,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policies.Policies).To(HaveLen(2))
+		Expect(policies.Policies[0].ID).To(Equal("id3"))
+		Expect(policies.Policies[1].ID).To(Equal("id4"))
+
+		roles, err := tenant.ListOryAccessControlRole(ketoclient.Exact, &ketoclient.ListORYAccessRoleRequest{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(roles.Roles).To(Equal([]ketoclient.ORYAccessControlRole{
+			{ID: "role2", Members: []string{"user"}},
+		}))
+	})
+
+	It("should manage the members of the roles", func() {
+		_, err := tenant.UpsertOryAccessControlRole(ketoclient.Exact, &ketoclient.UpsertORYAccessRoleRequest{
+			Role: ketoclient.ORYAccessControlRole{ID: "role1", Members: []string{"snake-eyes"}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		response, err := tenant.AddMembersOryAccessControlRole(ketoclient.Exact, "role1", &ketoclient.AddMembersORYAccessRoleRequest{
+			Members: []string{"scarlet"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Role.Members).To(ConsistOf("snake-eyes", "scarlet"))
+		Expect(keto.roles[ketoclient.Exact][0].Members).To(ConsistOf("acme:snake-eyes", "acme:scarlet"))
+
+		Expect(tenant.RemoveMemberOryAccessControlRole(ketoclient.Exact, "role1", "scarlet")).To(Succeed())
+
+		getResponse, err := tenant.GetOryAccessControlRole(ketoclient.Exact, "role1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getResponse.Role).To(Equal(ketoclient.ORYAccessControlRole{ID: "role1", Members: []string{"snake-eyes"}}))
+	})
+
+	It("should refuse data that crosses the tenant boundaries", func() {
+		keto.AddPolicies(ketoclient.Exact, ketoclient.ORYAccessControlPolicy{
+			ID:       "acme:id1",
+			Subjects: []string{"acme:user", "other:user"},
+		})
+
+		_, err := tenant.GetOryAccessControlPolicy(ketoclient.Exact, "id1")
+		Expect(errors.Is(err, ketoclient.ErrCrossTenant)).To(BeTrue())
+
+		_, err = tenant.ListOryAccessControlPolicy(ketoclient.Exact, &ketoclient.ListORYAccessPolicyRequest{})
+		Expect(errors.Is(err, ketoclient.ErrCrossTenant)).To(BeTrue())
+
+		err = tenant.DeleteOryAccessControlPolicy(ketoclient.Exact, "../../other:id1")
+		Expect(errors.Is(err, ketoclient.ErrCrossTenant)).To(BeTrue())
+	})
+
+	It("should refuse the policies comparing the context against the subject", func() {
+		_, err := tenant.UpsertOryAccessControlPolicy(ketoclient.Exact, &ketoclient.UpsertORYAccessPolicyRequest{
+			ORYAccessControlPolicy: ketoclient.ORYAccessControlPolicy{
+				ID:         "id1",
+				Subjects:   []string{"user:snake-eyes"},
+				Resources:  []string{"blog1:post:33"},
+				Actions:    []string{"delete"},
+				Effect:     ketoclient.Allow,
+				Conditions: map[string]interface{}{"owner": map[string]interface{}{"type": "EqualsSubjectCondition"}},
+			},
+		})
+		Expect(errors.Is(err, ketoclient.ErrUnsupportedCondition)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("owner")))
+		Expect(keto.policies[ketoclient.Exact]).To(BeEmpty())
+
+		_, err = tenant.UpsertOryAccessControlPolicy(ketoclient.Exact, &ketoclient.UpsertORYAccessPolicyRequest{
+			ORYAccessControlPolicy: ketoclient.ORYAccessControlPolicy{
+				ID:         "id1",
+				Subjects:   []string{"user:snake-eyes"},
+				Resources:  []string{"blog1:post:33"},
+				Actions:    []string{"delete"},
+				Effect:     ketoclient.Allow,
+				Conditions: map[string]interface{}{"public": map[string]interface{}{"type": "BooleanCondition", "options": map[string]interface{}{"value": true}}},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keto.policies[ketoclient.Exact]).To(HaveLen(1))
+	})
+
+	It("should validate the arguments before prefixing them", func() {
+		invalid := func(err error, fields ...string) {
+			var validation *ketoclient.ValidationError
+			Expect(err).To(BeAssignableToTypeOf(validation))
+			validation = err.(*ketoclient.ValidationError)
+			names := make([]string, len(validation.Fields))
+			for i, field := range validation.Fields {
+				names[i] = field.Field
+			}
+			Expect(names).To(Equal(fields))
+		}
+
+		_, err := tenant.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{Action: "delete"})
+		invalid(err, "subject", "resource")
+		_, err = tenant.AllowedOryAccessControlPolicy(ketoclient.Exact, nil)
+		invalid(err, "request")
+		_, err = tenant.UpsertOryAccessControlPolicy(ketoclient.Exact, &ketoclient.UpsertORYAccessPolicyRequest{
+			ORYAccessControlPolicy: ketoclient.ORYAccessControlPolicy{ID: "id1", Subjects: []string{""}},
+		})
+		invalid(err, "subjects[0]")
+		_, err = tenant.UpsertOryAccessControlPolicy(ketoclient.Exact, nil)
+		invalid(err, "request")
+		_, err = tenant.ListOryAccessControlPolicy(ketoclient.Exact, nil)
+		invalid(err, "request")
+		_, err = tenant.GetOryAccessControlPolicy(ketoclient.Exact, "")
+		invalid(err, "id")
+		invalid(tenant.DeleteOryAccessControlPolicy(ketoclient.Exact, ""), "id")
+
+		_, err = tenant.UpsertOryAccessControlRole(ketoclient.Exact, &ketoclient.UpsertORYAccessRoleRequest{})
+		invalid(err, "id")
+		_, err = tenant.UpsertOryAccessControlRole(ketoclient.Exact, nil)
+		invalid(err, "request")
+		_, err = tenant.ListOryAccessControlRole(ketoclient.Exact, nil)
+		invalid(err, "request")
+		_, err = tenant.GetOryAccessControlRole(ketoclient.Exact, "")
+		invalid(err, "id")
+		invalid(tenant.DeleteOryAccessControlRole(ketoclient.Exact, ""), "id")
+		_, err = tenant.AddMembersOryAccessControlRole(ketoclient.Exact, "role1", &ketoclient.AddMembersORYAccessRoleRequest{Members: []string{""}})
+		invalid(err, "members[0]")
+		_, err = tenant.AddMembersOryAccessControlRole(ketoclient.Exact, "role1", nil)
+		invalid(err, "request")
+		invalid(tenant.RemoveMemberOryAccessControlRole(ketoclient.Exact, "role1", ""), "member")
+
+		Expect(keto.Requests()).To(BeZero())
+	})
+})
@@ -0,0 +1,139 @@
+package ketoclient
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/lab259/errors/v2"
+	"golang.org/x/time/rate"
+)
+
+// ErrThrottled is returned when a request could not acquire its rate limit
+// token or in-flight slot before the context was done.
+var ErrThrottled = errors.New("request throttled")
+
+// ThrottleStats reports how much a category of operations was throttled.
+type ThrottleStats struct {
+	// Throttled is the number of requests that had to wait.
+	Throttled int64
+
+	// Rejected is the number of requests that gave up waiting because their
+	// context was done.
+	Rejected int64
+
+	// WaitTime is the total time spent waiting.
+	WaitTime time.Duration
+
+	// InFlight is the number of requests of the category being sent.
+	InFlight int64
+}
+
+// throttle applies a token bucket rate limit and a maximum number of
+// in-flight requests to a category of operations.
+//
+// A nil throttle does not limit anything.
+type throttle struct {
+	// Accessed atomically, kept first for 64-bit alignment.
+	throttled int64
+	rejected  int64
+	waitTime  int64
+	inFlight  int64
+
+	limiter *rate.Limiter
+	slots   chan struct{}
+}
+
+func noRelease() {}
+
+// acquire waits for a rate limit token and an in-flight slot. The returned
+// function must be called to release the slot.
+func (t *throttle) acquire(ctx context.Context) (func(), error) {
+	if t == nil {
+		return noRelease, nil
+	}
+
+	start := time.Now()
+	waited := false
+	defer func() {
+		if waited {
+			atomic.AddInt64(&t.throttled, 1)
+			atomic.AddInt64(&t.waitTime, int64(time.Since(start)))
+		}
+	}()
+
+	if t.limiter != nil {
+		reservation := t.limiter.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			waited = true
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+				reservation.Cancel()
+				atomic.AddInt64(&t.rejected, 1)
+				return nil, errors.Wrap(ErrThrottled, errors.Message("rate limit would exceed the context deadline"))
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				reservation.Cancel()
+				atomic.AddInt64(&t.rejected, 1)
+				return nil, errors.Wrap(ErrThrottled, errors.Message(ctx.Err().Error()))
+			}
+		}
+	}
+
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		default:
+			waited = true
+			select {
+			case t.slots <- struct{}{}:
+			case <-ctx.Done():
+				atomic.AddInt64(&t.rejected, 1)
+				return nil, errors.Wrap(ErrThrottled, errors.Message(ctx.Err().Error()))
+			}
+		}
+	}
+
+	atomic.AddInt64(&t.inFlight, 1)
+	return func() {
+		atomic.AddInt64(&t.inFlight, -1)
+		if t.slots != nil {
+			<-t.slots
+		}
+	}, nil
+}
+
+func (t *throttle) stats() ThrottleStats {
+	if t == nil {
+		return ThrottleStats{}
+	}
+	return ThrottleStats{
+		Throttled: atomic.LoadInt64(&t.throttled),
+		Rejected:  atomic.LoadInt64(&t.rejected),
+		WaitTime:  time.Duration(atomic.LoadInt64(&t.waitTime)),
+		InFlight:  atomic.LoadInt64(&t.inFlight),
+	}
+}
+
+// ThrottleStats returns the throttling statistics of a category of
+// operations. Categories without limits always report zero.
+func (client *Client) ThrottleStats(category Category) ThrottleStats {
+	return client.throttles[category].stats()
+}
+
+// throttle returns the throttle of the category, creating it when needed.
+// It must only be used while the client is being created.
+func (client *Client) throttle(category Category) *throttle {
+	if client.throttles == nil {
+		client.throttles = make(map[Category]*throttle)
+	}
+	t, ok := client.throttles[category]
+	if !ok {
+		t = &throttle{}
+		client.throttles[category] = t
+	}
+	return t
+}
@@ -0,0 +1,106 @@
+package ketoclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/lab259/errors/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/time/rate"
+)
+
+var _ = Describe("throttle", func() {
+	It("should not limit when nil", func() {
+		var t *throttle
+		release, err := t.acquire(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		release()
+		Expect(t.stats()).To(Equal(ThrottleStats{}))
+	})
+
+	It("should wait for a rate limit token", func() {
+		t := &throttle{limiter: rate.NewLimiter(rate.Every(50*time.Millisecond), 1)}
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			release, err := t.acquire(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			release()
+		}
+		Expect(time.Since(start)).To(BeNumerically(">=", 90*time.Millisecond))
+
+		stats := t.stats()
+		Expect(stats.Throttled).To(Equal(int64(2)))
+		Expect(stats.WaitTime).To(BeNumerically(">=", 90*time.Millisecond))
+	})
+
+	It("should not wait beyond the context deadline", func() {
+		t := &throttle{limiter: rate.NewLimiter(rate.Every(time.Second), 1)}
+
+		release, err := t.acquire(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err = t.acquire(ctx)
+		Expect(errors.Is(err, ErrThrottled)).To(BeTrue())
+		Expect(time.Since(start)).To(BeNumerically("<", 10*time.Millisecond))
+		Expect(t.stats().Rejected).To(Equal(int64(1)))
+	})
+
+	It("should cap the requests in flight", func() {
+		t := &throttle{slots: make(chan struct{}, 1)}
+
+		release, err := t.acquire(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.stats().InFlight).To(Equal(int64(1)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = t.acquire(ctx)
+		Expect(errors.Is(err, ErrThrottled)).To(BeTrue())
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			release()
+		}()
+		release2, err := t.acquire(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		release2()
+
+		stats := t.stats()
+		Expect(stats.InFlight).To(BeZero())
+		Expect(stats.Throttled).To(Equal(int64(2)))
+		Expect(stats.Rejected).To(Equal(int64(1)))
+	})
+
+	It("should limit only the configured category", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		client := New(
+			WithURL(u),
+			WithRateLimit(CategoryCheck, 1, 1),
+		)
+		request := &AllowedORYAccessControlPolicyRequest{Subject: "a", Action: "b", Resource: "c"}
+		_, err = client.AllowedOryAccessControlPolicy(Exact, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = client.WithContext(ctx).AllowedOryAccessControlPolicy(Exact, request)
+		Expect(errors.Is(err, ErrThrottled)).To(BeTrue())
+		Expect(client.ThrottleStats(CategoryCheck).Rejected).To(Equal(int64(1)))
+		Expect(client.ThrottleStats(CategoryWrite)).To(Equal(ThrottleStats{}))
+	})
+})
@@ -0,0 +1,66 @@
+package ketoclient
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans created by the client.
+const instrumentationName = "github.com/lab259/ory-keto-client"
+
+// Attributes set on the spans of the client operations.
+const (
+	AttributeOperation = attribute.Key("keto.operation")
+	AttributeFlavor    = attribute.Key("keto.flavor")
+	AttributePolicyID  = attribute.Key("keto.policy.id")
+	AttributeRoleID    = attribute.Key("keto.role.id")
+	AttributeMember    = attribute.Key("keto.role.member")
+	AttributeSubject   = attribute.Key("keto.subject")
+	AttributeAction    = attribute.Key("keto.action")
+	AttributeResource  = attribute.Key("keto.resource")
+	AttributeAllowed   = attribute.Key("keto.allowed")
+	AttributeStale     = attribute.Key("keto.stale")
+
+	attributeHTTPMethod    = attribute.Key("http.request.method")
+	attributeHTTPStatus    = attribute.Key("http.response.status_code")
+	attributeServerAddress = attribute.Key("server.address")
+)
+
+// subject returns the attribute of a subject after its redaction.
+func (client *Client) subject(subject string) attribute.KeyValue {
+	return AttributeSubject.String(client.redactSubject(subject))
+}
+
+// resource returns the attribute of a resource after its redaction.
+func (client *Client) resource(resource string) attribute.KeyValue {
+	return AttributeResource.String(client.redactResource(resource))
+}
+
+// member returns the attribute of a role member, which is a subject, after
+// its redaction.
+func (client *Client) member(member string) attribute.KeyValue {
+	return AttributeMember.String(client.redactSubject(member))
+}
+
+// traceRequest records the request on the span of the operation and injects
+// its trace context into the headers.
+func (client *Client) traceRequest(request *http.Request, endpoint string) {
+	ctx := request.Context()
+	trace.SpanFromContext(ctx).SetAttributes(
+		attributeHTTPMethod.String(request.Method),
+		attributeServerAddress.String(endpoint),
+	)
+	client.propagator.Inject(ctx, propagation.HeaderCarrier(request.Header))
+}
+
+// traceResponse records the status of the response on the span of the
+// operation.
+func traceResponse(request *http.Request, response *http.Response) {
+	if response == nil {
+		return
+	}
+	trace.SpanFromContext(request.Context()).SetAttributes(attributeHTTPStatus.Int(response.StatusCode))
+}
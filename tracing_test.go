@@ -0,0 +1,140 @@
+package ketoclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanAttributes indexes the attributes of a recorded span by their key.
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+var _ = Describe("Tracing", func() {
+	var (
+		keto     *fakeKeto
+		exporter *tracetest.InMemoryExporter
+		provider *sdktrace.TracerProvider
+	)
+
+	BeforeEach(func() {
+		keto = newFakeKeto()
+		keto.AddPolicies(ketoclient.Exact, ketoclient.ORYAccessControlPolicy{
+			ID:        "policy1",
+			Subjects:  []string{"user:snake-eyes"},
+			Actions:   []string{"delete"},
+			Resources: []string{"blog1:post:33"},
+			Effect:    ketoclient.Allow,
+		})
+		exporter = tracetest.NewInMemoryExporter()
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	})
+
+	AfterEach(func() {
+		keto.Close()
+		Expect(provider.Shutdown(context.Background())).To(Succeed())
+	})
+
+	It("should create a span for each operation", func() {
+		client := keto.Client(ketoclient.WithTracerProvider(provider))
+		_, err := client.GetOryAccessControlPolicy(ketoclient.Exact, "policy1")
+		Expect(err).ToNot(HaveOccurred())
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name).To(Equal("keto.GetOryAccessControlPolicy"))
+		Expect(spans[0].SpanKind).To(Equal(trace.SpanKindClient))
+		Expect(spans[0].Status.Code).To(Equal(codes.Unset))
+
+		attrs := spanAttributes(spans[0])
+		Expect(attrs[ketoclient.AttributeOperation].AsString()).To(Equal("GetOryAccessControlPolicy"))
+		Expect(attrs[ketoclient.AttributeFlavor].AsString()).To(Equal("exact"))
+		Expect(attrs[ketoclient.AttributePolicyID].AsString()).To(Equal("policy1"))
+		Expect(attrs["http.request.method"].AsString()).To(Equal(http.MethodGet))
+		Expect(attrs["http.response.status_code"].AsInt64()).To(Equal(int64(http.StatusOK)))
+	})
+
+	It("should redact the subjects by default", func() {
+		client := keto.Client(ketoclient.WithTracerProvider(provider))
+		response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{
+			Subject:  "user:snake-eyes",
+			Action:   "delete",
+			Resource: "blog1:post:33",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Allowed).To(BeTrue())
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		attrs := spanAttributes(spans[0])
+		Expect(attrs[ketoclient.AttributeSubject].AsString()).To(Equal("[redacted]"))
+		Expect(attrs[ketoclient.AttributeAction].AsString()).To(Equal("delete"))
+		Expect(attrs[ketoclient.AttributeAllowed].AsBool()).To(BeTrue())
+	})
+
+	It("should apply the configured subject redaction", func() {
+		client := keto.Client(ketoclient.WithTracerProvider(provider), ketoclient.WithSubjectRedactor(ketoclient.RedactHash))
+		Expect(client.RemoveMemberOryAccessControlRole(ketoclient.Exact, "role1", "user:snake-eyes")).To(Succeed())
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		attrs := spanAttributes(spans[0])
+		Expect(attrs[ketoclient.AttributeRoleID].AsString()).To(Equal("role1"))
+		Expect(attrs[ketoclient.AttributeMember].AsString()).To(Equal(ketoclient.RedactHash("user:snake-eyes")))
+		Expect(attrs[ketoclient.AttributeMember].AsString()).To(HavePrefix("sha256:"))
+	})
+
+	It("should record the errors", func() {
+		client := keto.Client(ketoclient.WithTracerProvider(provider))
+		_, err := client.GetOryAccessControlPolicy(ketoclient.Exact, "unknown")
+		Expect(err).To(Equal(ketoclient.ErrNotFound))
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status.Code).To(Equal(codes.Error))
+		Expect(spans[0].Status.Description).To(Equal(ketoclient.ErrNotFound.Error()))
+		Expect(spans[0].Events).To(HaveLen(1))
+		Expect(spans[0].Events[0].Name).To(Equal("exception"))
+		Expect(spanAttributes(spans[0])["http.response.status_code"].AsInt64()).To(Equal(int64(http.StatusNotFound)))
+	})
+
+	It("should propagate the trace context to the server", func() {
+		headers := make(chan http.Header, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers <- r.Header
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		}))
+		defer server.Close()
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+		client := ketoclient.New(ketoclient.WithURL(u), withUniqueBreakerPrefix(), ketoclient.WithTracerProvider(provider))
+		_, err = client.WithContext(ctx).HealthAlive()
+		Expect(err).ToNot(HaveOccurred())
+		parent.End()
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Name).To(Equal("keto.HealthAlive"))
+		Expect(spans[0].Parent.SpanID()).To(Equal(parent.SpanContext().SpanID()))
+
+		header := <-headers
+		Expect(header.Get("traceparent")).To(Equal("00-" + spans[0].SpanContext.TraceID().String() + "-" + spans[0].SpanContext.SpanID().String() + "-01"))
+	})
+})
@@ -0,0 +1,188 @@
+package ketoclient
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lab259/errors/v2"
+)
+
+// ErrInvalidRequest is the reason of all `*ValidationError`.
+var ErrInvalidRequest = errors.New("invalid request")
+
+// FieldError is an invalid argument of a call.
+type FieldError struct {
+	// Field is the name of the argument or, for the fields of a request, its
+	// JSON name: `flavor`, `subject` or `members[1]` for instance.
+	Field string
+
+	// Reason explains why the field is invalid.
+	Reason string
+}
+
+func (err FieldError) String() string {
+	return err.Field + " " + err.Reason
+}
+
+// ValidationError is returned, before anything is sent, when the arguments
+// of a call are invalid. It lists all the invalid fields.
+type ValidationError struct {
+	Operation string
+	Fields    []FieldError
+}
+
+func (err *ValidationError) Error() string {
+	fields := make([]string, len(err.Fields))
+	for i, field := range err.Fields {
+		fields[i] = field.String()
+	}
+	return fmt.Sprintf("invalid %s request: %s", err.Operation, strings.Join(fields, ", "))
+}
+
+// Unwrap returns `ErrInvalidRequest`.
+func (err *ValidationError) Unwrap() error {
+	return ErrInvalidRequest
+}
+
+// validation collects the invalid fields of the arguments of a call.
+type validation struct {
+	operation string
+	fields    []FieldError
+}
+
+func validate(operation string) *validation {
+	return &validation{operation: operation}
+}
+
+func (v *validation) invalid(field, reason string) *validation {
+	v.fields = append(v.fields, FieldError{Field: field, Reason: reason})
+	return v
+}
+
+// flavor checks that the flavor is one of the flavors of the ACP engines.
+func (v *validation) flavor(flavor Flavor) *validation {
+	switch {
+	case flavor.known():
+		return v
+	case flavor == "":
+		return v.invalid("flavor", "is empty")
+	default:
+		return v.invalid("flavor", fmt.Sprintf("is unknown: %q", string(flavor)))
+	}
+}
+
+// present checks that a request was given. The fields of a missing request
+// are not checked.
+func (v *validation) present(field string, present bool) bool {
+	if !present {
+		v.invalid(field, "is missing")
+	}
+	return present
+}
+
+func (v *validation) required(field, value string) *validation {
+	if value == "" {
+		v.invalid(field, "is empty")
+	}
+	return v
+}
+
+// each checks that none of the values is empty.
+func (v *validation) each(field string, values []string) *validation {
+	for i, value := range values {
+		v.required(fmt.Sprintf("%s[%d]", field, i), value)
+	}
+	return v
+}
+
+func (v *validation) page(limit, offset int64) *validation {
+	if limit < 0 {
+		v.invalid("limit", "is negative")
+	}
+	if offset < 0 {
+		v.invalid("offset", "is negative")
+	}
+	return v
+}
+
+func (v *validation) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Operation: v.operation, Fields: v.fields}
+}
+
+// policy checks the fields of a policy sent to the server.
+func (v *validation) policy(policy *ORYAccessControlPolicy) *validation {
+	v.required("id", policy.ID)
+	v.each("subjects", policy.Subjects).each("actions", policy.Actions).each("resources", policy.Resources)
+	switch policy.Effect {
+	case "", Allow, Deny:
+	default:
+		v.invalid("effect", fmt.Sprintf("is unknown: %q", string(policy.Effect)))
+	}
+	return v
+}
+
+// The validations of the operations, shared by `Client` and `TenantClient`,
+// which checks the arguments before prefixing them with the tenant.
+
+func validateAllowed(flavor Flavor, request *AllowedORYAccessControlPolicyRequest) error {
+	v := validate("AllowedOryAccessControlPolicy").flavor(flavor)
+	if v.present("request", request != nil) {
+		v.required("subject", request.Subject).required("action", request.Action).required("resource", request.Resource)
+	}
+	return v.err()
+}
+
+func validateUpsertPolicy(flavor Flavor, request *UpsertORYAccessPolicyRequest) error {
+	v := validate("UpsertOryAccessControlPolicy").flavor(flavor)
+	if v.present("request", request != nil) {
+		v.policy(&request.ORYAccessControlPolicy)
+	}
+	return v.err()
+}
+
+func validateListPolicies(flavor Flavor, request *ListORYAccessPolicyRequest) error {
+	v := validate("ListOryAccessControlPolicy").flavor(flavor)
+	if v.present("request", request != nil) {
+		v.page(request.Limit, request.Offset)
+	}
+	return v.err()
+}
+
+// validateID checks the arguments of the operations that only take an ID.
+func validateID(operation string, flavor Flavor, id string) error {
+	return validate(operation).flavor(flavor).required("id", id).err()
+}
+
+func validateUpsertRole(flavor Flavor, request *UpsertORYAccessRoleRequest) error {
+	v := validate("UpsertOryAccessControlRole").flavor(flavor)
+	if v.present("request", request != nil) {
+		v.required("id", request.Role.ID).each("members", request.Role.Members)
+	}
+	return v.err()
+}
+
+func validateListRoles(flavor Flavor, request *ListORYAccessRoleRequest) error {
+	v := validate("ListOryAccessControlRole").flavor(flavor)
+	if v.present("request", request != nil) {
+		v.page(request.Limit, request.Offset)
+	}
+	return v.err()
+}
+
+func validateAddMembers(flavor Flavor, id string, request *AddMembersORYAccessRoleRequest) error {
+	v := validate("AddMembersOryAccessControlRole").flavor(flavor).required("id", id)
+	if v.present("request", request != nil) {
+		if len(request.Members) == 0 {
+			v.invalid("members", "is empty")
+		}
+		v.each("members", request.Members)
+	}
+	return v.err()
+}
+
+func validateRemoveMember(flavor Flavor, id, member string) error {
+	return validate("RemoveMemberOryAccessControlRole").flavor(flavor).required("id", id).required("member", member).err()
+}
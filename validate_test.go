@@ -0,0 +1,114 @@
+package ketoclient_test
+
+import (
+	"time"
+
+	"github.com/lab259/errors/v2"
+	ketoclient "github.com/lab259/ory-keto-client"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validation", func() {
+	var (
+		keto   *fakeKeto
+		client *ketoclient.Client
+	)
+
+	BeforeEach(func() {
+		keto = newFakeKeto()
+		client = keto.Client()
+	})
+
+	AfterEach(func() {
+		keto.Close()
+	})
+
+	field := func(name, reason string) ketoclient.FieldError {
+		return ketoclient.FieldError{Field: name, Reason: reason}
+	}
+
+	table.DescribeTable("should reject the invalid calls without sending them",
+		func(call func(client *ketoclient.Client) error, operation string, fields ...ketoclient.FieldError) {
+			err := call(client)
+			Expect(errors.Is(err, ketoclient.ErrInvalidRequest)).To(BeTrue())
+			Expect(err).To(BeAssignableToTypeOf(&ketoclient.ValidationError{}))
+			Expect(err.(*ketoclient.ValidationError).Operation).To(Equal(operation))
+			Expect(err.(*ketoclient.ValidationError).Fields).To(Equal(fields))
+			Expect(keto.Requests()).To(BeZero())
+		},
+		table.Entry("check without flavor nor fields", func(client *ketoclient.Client) error {
+			_, err := client.AllowedOryAccessControlPolicy("", &ketoclient.AllowedORYAccessControlPolicyRequest{})
+			return err
+		}, "AllowedOryAccessControlPolicy", field("flavor", "is empty"), field("subject", "is empty"), field("action", "is empty"), field("resource", "is empty")),
+		table.Entry("check with an unknown flavor", func(client *ketoclient.Client) error {
+			_, err := client.AllowedOryAccessControlPolicy("wildcard", &ketoclient.AllowedORYAccessControlPolicyRequest{
+				Subject:  "user:snake-eyes",
+				Action:   "delete",
+				Resource: "blog1:post:33",
+			})
+			return err
+		}, "AllowedOryAccessControlPolicy", field("flavor", `is unknown: "wildcard"`)),
+		table.Entry("check without request", func(client *ketoclient.Client) error {
+			_, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, nil)
+			return err
+		}, "AllowedOryAccessControlPolicy", field("request", "is missing")),
+		table.Entry("policy without id", func(client *ketoclient.Client) error {
+			_, err := client.UpsertOryAccessControlPolicy(ketoclient.Glob, &ketoclient.UpsertORYAccessPolicyRequest{
+				ORYAccessControlPolicy: ketoclient.ORYAccessControlPolicy{
+					Subjects: []string{"user:snake-eyes", ""},
+					Effect:   "permit",
+				},
+			})
+			return err
+		}, "UpsertOryAccessControlPolicy", field("id", "is empty"), field("subjects[1]", "is empty"), field("effect", `is unknown: "permit"`)),
+		table.Entry("policies page", func(client *ketoclient.Client) error {
+			_, err := client.ListOryAccessControlPolicy(ketoclient.Exact, &ketoclient.ListORYAccessPolicyRequest{Limit: -1, Offset: -1})
+			return err
+		}, "ListOryAccessControlPolicy", field("limit", "is negative"), field("offset", "is negative")),
+		table.Entry("policy id", func(client *ketoclient.Client) error {
+			_, err := client.GetOryAccessControlPolicy(ketoclient.Exact, "")
+			return err
+		}, "GetOryAccessControlPolicy", field("id", "is empty")),
+		table.Entry("deleted policy id", func(client *ketoclient.Client) error {
+			return client.DeleteOryAccessControlPolicy("", "")
+		}, "DeleteOryAccessControlPolicy", field("flavor", "is empty"), field("id", "is empty")),
+		table.Entry("role without id", func(client *ketoclient.Client) error {
+			_, err := client.UpsertOryAccessControlRole(ketoclient.Exact, &ketoclient.UpsertORYAccessRoleRequest{
+				Role: ketoclient.ORYAccessControlRole{Members: []string{""}},
+			})
+			return err
+		}, "UpsertOryAccessControlRole", field("id", "is empty"), field("members[0]", "is empty")),
+		table.Entry("role id", func(client *ketoclient.Client) error {
+			_, err := client.GetOryAccessControlRole(ketoclient.Regex, "")
+			return err
+		}, "GetOryAccessControlRole", field("id", "is empty")),
+		table.Entry("roles without request", func(client *ketoclient.Client) error {
+			_, err := client.ListOryAccessControlRole(ketoclient.Exact, nil)
+			return err
+		}, "ListOryAccessControlRole", field("request", "is missing")),
+		table.Entry("deleted role id", func(client *ketoclient.Client) error {
+			return client.DeleteOryAccessControlRole(ketoclient.Exact, "")
+		}, "DeleteOryAccessControlRole", field("id", "is empty")),
+		table.Entry("no members to add", func(client *ketoclient.Client) error {
+			_, err := client.AddMembersOryAccessControlRole(ketoclient.Exact, "role1", &ketoclient.AddMembersORYAccessRoleRequest{})
+			return err
+		}, "AddMembersOryAccessControlRole", field("members", "is empty")),
+		table.Entry("removed member", func(client *ketoclient.Client) error {
+			return client.RemoveMemberOryAccessControlRole(ketoclient.Exact, "", "")
+		}, "RemoveMemberOryAccessControlRole", field("id", "is empty"), field("member", "is empty")),
+	)
+
+	It("should describe every invalid field", func() {
+		_, err := client.AllowedOryAccessControlPolicy("", &ketoclient.AllowedORYAccessControlPolicyRequest{Subject: "user:snake-eyes"})
+		Expect(err).To(MatchError("invalid AllowedOryAccessControlPolicy request: flavor is empty, action is empty, resource is empty"))
+	})
+
+	It("should not replace the invalid checks by stored decisions", func() {
+		client = keto.Client(ketoclient.WithStaleOnError(ketoclient.StaleOnError{Window: time.Minute, DefaultAllowed: true}))
+		response, err := client.AllowedOryAccessControlPolicy(ketoclient.Exact, &ketoclient.AllowedORYAccessControlPolicyRequest{})
+		Expect(errors.Is(err, ketoclient.ErrInvalidRequest)).To(BeTrue())
+		Expect(response).To(BeNil())
+	})
+})